@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultExecTimeout はmei execでプロジェクトごとにコマンドを実行するときのデフォルトのタイムアウトです
+const defaultExecTimeout = 10 * time.Minute
+
+// execResult はプロジェクトでコマンドを実行した結果です
+type execResult struct {
+	Project  string
+	Duration time.Duration
+	Err      error
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] -- <command...>",
+	Short: "登録されているプロジェクトのディレクトリでコマンドを実行します",
+	Args:  cobra.MinimumNArgs(1),
+	// コマンドの失敗は使い方の誤りではないため使い方を表示しない
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projects, err := loadProjects()
+		if err != nil {
+			return err
+		}
+		tag, _ := cmd.Flags().GetString("tag")
+		projects = filterProjectsByTag(projects, tag)
+		if len(projects) == 0 {
+			fmt.Println("対象のプロジェクトはありません")
+			return nil
+		}
+
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		// 出力の行が混ざらないように、すべてのプロジェクトで1つのロックを共有する
+		var mu sync.Mutex
+		results := make([]execResult, len(projects))
+		forEachProjectParallel(projects, jobs, func(i int, project Project) {
+			results[i] = runProjectCommand(project, args, timeout, &mu)
+		})
+
+		return printExecSummary(results)
+	},
+}
+
+// runProjectCommand はプロジェクトのディレクトリでコマンドを実行し、出力の各行にプロジェクト名を付けて表示します
+// 引数が1つの場合はシェルのコマンドとして実行するため、パイプなども使えます
+func runProjectCommand(project Project, args []string, timeout time.Duration, mu *sync.Mutex) execResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	shellArgs := []string{"-c", args[0]}
+	if len(args) > 1 {
+		shellArgs = append([]string{"-c", `"$@"`, "sh"}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "sh", shellArgs...)
+	cmd.Dir = project.Path
+	cmd.Env = append(os.Environ(), hookEnv(project)...)
+	// タイムアウト後に子プロセスが出力を開いたままでも待ち続けないようにする
+	cmd.WaitDelay = time.Second
+
+	prefix := fmt.Sprintf("[%s] ", project.Name)
+	stdout := &prefixWriter{mu: mu, out: os.Stdout, prefix: prefix}
+	stderr := &prefixWriter{mu: mu, out: os.Stderr, prefix: prefix}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%sでタイムアウトしました", timeout)
+	}
+	stdout.Flush()
+	stderr.Flush()
+
+	return execResult{Project: project.Name, Duration: time.Since(start), Err: err}
+}
+
+// printExecSummary は成功・失敗の集計を表示し、失敗したプロジェクトがあればエラーを返します
+func printExecSummary(results []execResult) error {
+	fmt.Println()
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("✗ %s（失敗: %v、%s）\n", result.Project, result.Err, result.Duration.Round(time.Millisecond))
+		} else {
+			fmt.Printf("✓ %s（%s）\n", result.Project, result.Duration.Round(time.Millisecond))
+		}
+	}
+	fmt.Printf("成功: %d、失敗: %d\n", len(results)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%d件のプロジェクトでコマンドが失敗しました", failed)
+	}
+	return nil
+}
+
+// prefixWriter は書き込まれた内容を行ごとに接頭辞を付けて出力します
+// 行の途中までの内容はFlushするか改行が書き込まれるまで保持します
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush は改行で終わっていない最後の行を出力します
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintln(w.out, w.prefix+strings.TrimSuffix(line, "\r"))
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().String("tag", "", "指定したタグが付いたプロジェクトだけを対象にします")
+	execCmd.Flags().Int("jobs", runtime.NumCPU(), "並行してコマンドを実行するプロジェクト数を指定します")
+	execCmd.Flags().Duration("timeout", defaultExecTimeout, "プロジェクトごとのコマンドのタイムアウトを指定します")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string // Flushする前の出力
+		flush  string // Flushで追加される出力
+	}{
+		{
+			name:   "complete lines",
+			writes: []string{"one\ntwo\n"},
+			want:   "[app] one\n[app] two\n",
+		},
+		{
+			name:   "line split across writes",
+			writes: []string{"he", "llo", " world\nne", "xt\n"},
+			want:   "[app] hello world\n[app] next\n",
+		},
+		{
+			name:   "missing final newline",
+			writes: []string{"one\ntw", "o"},
+			want:   "[app] one\n",
+			flush:  "[app] two\n",
+		},
+		{
+			name:   "crlf",
+			writes: []string{"one\r\ntwo\r", "\n"},
+			want:   "[app] one\n[app] two\n",
+		},
+		{
+			name:   "empty lines",
+			writes: []string{"\n\n"},
+			want:   "[app] \n[app] \n",
+		},
+		{
+			name:   "nothing written",
+			writes: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := &prefixWriter{mu: &sync.Mutex{}, out: &out, prefix: "[app] "}
+			for _, p := range tt.writes {
+				if n, err := w.Write([]byte(p)); n != len(p) || err != nil {
+					t.Fatalf("Write(%q) = %d, %v", p, n, err)
+				}
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("Flush前の出力 = %q, want %q", got, tt.want)
+			}
+			w.Flush()
+			if got := out.String(); got != tt.want+tt.flush {
+				t.Errorf("Flush後の出力 = %q, want %q", got, tt.want+tt.flush)
+			}
+			// 2回目のFlushでは何も出力しない
+			w.Flush()
+			if got := out.String(); got != tt.want+tt.flush {
+				t.Errorf("2回目のFlush後の出力 = %q, want %q", got, tt.want+tt.flush)
+			}
+		})
+	}
+}
+
+func TestPrintExecSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []execResult
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "all succeeded",
+			results: []execResult{
+				{Project: "api", Duration: 1500 * time.Millisecond},
+				{Project: "web", Duration: 20 * time.Millisecond},
+			},
+			want: []string{"✓ api（1.5s）", "✓ web（20ms）", "成功: 2、失敗: 0"},
+		},
+		{
+			name: "non-zero exit",
+			results: []execResult{
+				{Project: "api", Duration: time.Second},
+				{Project: "web", Duration: time.Second, Err: errors.New("exit status 2")},
+			},
+			want:    []string{"✓ api（1s）", "✗ web（失敗: exit status 2、1s）", "成功: 1、失敗: 1"},
+			wantErr: "1件のプロジェクトでコマンドが失敗しました",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			output := captureStdout(t, func() {
+				err = printExecSummary(tt.results)
+			})
+			for _, want := range tt.want {
+				if !strings.Contains(output, want+"\n") {
+					t.Errorf("出力に %q がありません:\n%s", want, output)
+				}
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("printExecSummary() error = %v", err)
+				}
+			} else if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("printExecSummary() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunProjectCommandExitStatus(t *testing.T) {
+	project := Project{Name: "app", Path: t.TempDir()}
+	var result execResult
+	output := captureStdout(t, func() {
+		result = runProjectCommand(project, []string{"printf 'out'; exit 3"}, time.Minute, &sync.Mutex{})
+	})
+	if output != "[app] out\n" {
+		t.Errorf("出力 = %q, want %q", output, "[app] out\n")
+	}
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "exit status 3") {
+		t.Errorf("Err = %v, want exit status 3", result.Err)
+	}
+}
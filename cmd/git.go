@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"mei/internal/gitrepo"
+)
+
+// gitCommandTimeout はgitコマンド1回あたりのタイムアウトです
+const gitCommandTimeout = time.Minute
+
+// gitRunner はgitコマンドの実行に使うRunnerです
+// テストではコマンドを記録するだけのRunnerに差し替えます
+var gitRunner gitrepo.Runner = gitrepo.ExecRunner{}
+
+// runGitCommand は作業ツリーでGitコマンドを実行します
+// 失敗した場合はgitの標準エラー出力を含むエラーを返します
+func runGitCommand(workTree string, args ...string) error {
+	_, err := gitOutput(workTree, args...)
+	return err
+}
+
+// gitOutput は作業ツリーでGitコマンドを実行し、前後の空白を除いた標準出力を返します
+func gitOutput(workTree string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitCommandTimeout)
+	defer cancel()
+	output, err := gitRunner.Run(ctx, workTree, args...)
+	return strings.TrimSpace(output), err
+}
+
+// gitConfigGet は作業ツリーのリポジトリのgit configから値を取得します
+// キーが設定されていない場合（終了ステータス1）は空文字列を返し、それ以外の失敗はエラーを返します
+func gitConfigGet(workTree, key string) (string, error) {
+	value, err := gitOutput(workTree, "config", "--local", "--get", key)
+	var cmdErr *gitrepo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.ExitCode == 1 {
+		return "", nil
+	}
+	return value, err
+}
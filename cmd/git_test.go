@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"mei/internal/gitrepo"
+)
+
+// fakeGitRunner はgitを実行せずにコマンドを記録するRunnerです
+// outputsとerrorsには引数を空白で連結したもの（例: "config --get remote.origin.url"）をキーに応答を指定します
+type fakeGitRunner struct {
+	outputs map[string]string
+	errors  map[string]error
+
+	mu    sync.Mutex
+	calls []fakeGitCall
+}
+
+// fakeGitCall はfakeGitRunnerに記録されたgitコマンドです
+type fakeGitCall struct {
+	dir  string
+	args []string
+}
+
+// String は記録されたコマンドを「git config --local user.name Alice」の形式で返します
+func (c fakeGitCall) String() string {
+	return "git " + strings.Join(c.args, " ")
+}
+
+func (f *fakeGitRunner) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, fakeGitCall{dir: dir, args: append([]string(nil), args...)})
+
+	key := strings.Join(args, " ")
+	return f.outputs[key], f.errors[key]
+}
+
+// Calls は記録されたコマンドを実行順に返します
+func (f *fakeGitRunner) Calls() []fakeGitCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]fakeGitCall(nil), f.calls...)
+}
+
+// useFakeGit はテストの間だけgitRunnerをfakeに差し替えます
+func useFakeGit(t *testing.T, fake *fakeGitRunner) {
+	t.Helper()
+	previous := gitRunner
+	gitRunner = fake
+	t.Cleanup(func() { gitRunner = previous })
+}
+
+var _ gitrepo.Runner = (*fakeGitRunner)(nil)
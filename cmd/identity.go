@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// identityCmd はidentity関連のコマンドを表します
+var identityCmd = &cobra.Command{
+	Use:     "identity",
+	Aliases: []string{"id"},
+	Short:   "Gitのidentity（アカウント）関連のコマンド",
+}
+
+func init() {
+	rootCmd.AddCommand(identityCmd)
+}
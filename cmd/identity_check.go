@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mei/internal/config"
+	"mei/internal/gitrepo"
+	"github.com/spf13/cobra"
+)
+
+var identityCheckCmd = &cobra.Command{
+	Use:   "check [path]",
+	Short: "リポジトリのuser.emailとリモートが登録されているGitユーザーと一致するか確認します",
+	Args:  cobra.MaximumNArgs(1),
+	// 不一致は使い方の誤りではないため使い方を表示しない
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("現在のディレクトリを取得できませんでした: %w", err)
+		}
+		if len(args) > 0 {
+			if dir, err = filepath.Abs(args[0]); err != nil {
+				return err
+			}
+		}
+
+		repo, err := discoverRepo(dir)
+		if errors.Is(err, gitrepo.ErrNotRepository) {
+			return fmt.Errorf("%s はGitリポジトリではありません", dir)
+		}
+		if err != nil {
+			return fmt.Errorf("gitディレクトリの解決に失敗しました: %w", err)
+		}
+
+		siblings, err := repoProjects(repo)
+		if err != nil {
+			return err
+		}
+		project, ok := projectForDir(siblings, dir)
+		if !ok {
+			fmt.Printf("%s はgit_userが指定されたプロジェクトとして登録されていません\n", repo.WorkTree)
+			return nil
+		}
+
+		// pre-commitフックから--quietで実行する場合はidentityが未定義であることも表示しない
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		identity, defined, err := lookupIdentity(project.GitUser)
+		if err != nil {
+			return err
+		}
+		if !defined && !quiet {
+			printUndefinedIdentity(project.GitUser, identity)
+		}
+
+		problems := identityMismatches(identity, repo)
+		if len(problems) > 0 {
+			for _, problem := range problems {
+				fmt.Printf("✗ %s\n", problem)
+			}
+			return fmt.Errorf("%s のidentityが %s と一致しません（mei p sync で修正できます）", project.Name, project.GitUser)
+		}
+		if quiet {
+			return nil
+		}
+		fmt.Printf("✓ %s のidentityは %s と一致しています\n", project.Name, project.GitUser)
+		return nil
+	},
+}
+
+// projectForDir はdirを含むリポジトリに登録されたプロジェクト（siblings）から、git_userが指定されたものを返します
+// モノレポではdirを含むサブプロジェクトのうち最も深いものを選び、dirを含むものがない場合（リポジトリのルートなど）は最初に登録されたものを使います
+func projectForDir(siblings []repoProject, dir string) (Project, bool) {
+	var found, fallback *Project
+	for _, sibling := range siblings {
+		project := sibling.Project
+		if project.GitUser == "" {
+			continue
+		}
+		if fallback == nil {
+			fallback = &project
+		}
+		if containsPath(project.Path, dir) && (found == nil || len(project.Path) > len(found.Path)) {
+			found = &project
+		}
+	}
+	if found == nil {
+		found = fallback
+	}
+	if found == nil {
+		return Project{}, false
+	}
+	return *found, true
+}
+
+// containsPath はpathがparent自体かその配下にあるかを返します
+func containsPath(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// identityMismatches は実際に使われるuser.emailとoriginを、プロジェクトのidentityと比較して不一致を返します
+func identityMismatches(identity config.Identity, repo *gitrepo.Repo) []string {
+	var problems []string
+
+	// --localに限らず、グローバル設定やincludeIfを含めた実際の値と比較する
+	email, _ := gitOutput(repo.WorkTree, "config", "user.email")
+	if email != identity.Email {
+		problems = append(problems, fmt.Sprintf("user.emailが %q です（期待値: %s）", email, identity.Email))
+	}
+
+	// 同期で書き換える対象のoriginだけを確認する
+	if remoteURL, _ := gitOutput(repo.WorkTree, "config", "--get", "remote.origin.url"); remoteURL != "" {
+		remote, err := gitrepo.ParseRemote(remoteURL)
+		if err == nil && sameRemoteHost(remote.Host, identity) && remote.Host != identity.SSHHost {
+			problems = append(problems, fmt.Sprintf("originのホストが %s です（期待値: %s）", remote.Host, identity.SSHHost))
+		}
+	}
+	return problems
+}
+
+func init() {
+	identityCmd.AddCommand(identityCheckCmd)
+	identityCheckCmd.Flags().BoolP("quiet", "q", false, "一致している場合は何も表示しません")
+}
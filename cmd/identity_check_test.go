@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"mei/internal/gitrepo"
+)
+
+func TestProjectForDir(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "mono")
+	repo := &gitrepo.Repo{WorkTree: root, GitDir: filepath.Join(root, ".git"), CommonDir: filepath.Join(root, ".git")}
+	at := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+
+	api := Project{Name: "api", Path: at("services/api"), GitUser: "bob"}
+	web := Project{Name: "web", Path: at("services/web"), GitUser: "carol"}
+	legacy := Project{Name: "legacy", Path: at("services/api/legacy"), GitUser: "dave"}
+	mono := Project{Name: "mono", Path: root, GitUser: "alice"}
+	docs := Project{Name: "docs", Path: at("docs")}
+	other := Project{Name: "other", Path: filepath.Join(t.TempDir(), "other"), GitUser: "erin"}
+
+	tests := []struct {
+		name     string
+		projects []Project
+		dir      string
+		want     string
+	}{
+		{"sibling api", []Project{api, web}, at("services/api/src"), "api"},
+		{"sibling web", []Project{api, web}, at("services/web"), "web"},
+		{"sibling registered later", []Project{web, api}, at("services/api"), "api"},
+		{"prefix is not containment", []Project{web, api}, at("services/web-admin"), "web"},
+		{"nested deepest wins", []Project{legacy, mono, api}, at("services/api/legacy/cmd"), "legacy"},
+		{"nested parent", []Project{legacy, mono, api}, at("services/api/internal"), "api"},
+		{"nested root", []Project{legacy, api, mono}, at("services/web"), "mono"},
+		{"fallback to first registered", []Project{web, api}, at("tools"), "web"},
+		{"project without git_user is ignored", []Project{docs, api}, at("docs"), "api"},
+		{"other repository is ignored", []Project{other}, root, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// リポジトリの探索はファイルシステムを見ずに、root以下のパスをrepoとみなす
+			index := newProjectIndex(tt.projects)
+			index.discover = func(path string) (*gitrepo.Repo, error) {
+				if containsPath(root, path) {
+					return repo, nil
+				}
+				return nil, gitrepo.ErrNotRepository
+			}
+
+			got, ok := projectForDir(index.siblings(repo), tt.dir)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("projectForDir(%s) = %s; want none", tt.dir, got.Name)
+				}
+				return
+			}
+			if !ok || got.Name != tt.want {
+				t.Errorf("projectForDir(%s) = %s, %v; want %s", tt.dir, got.Name, ok, tt.want)
+			}
+		})
+	}
+}
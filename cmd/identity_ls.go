@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"mei/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var identityLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "~/.mei/identities.ymlに定義されているidentity一覧を表示します",
+	Run: func(cmd *cobra.Command, args []string) {
+		identities, err := config.LoadIdentities()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if len(identities.Identities) == 0 {
+			fmt.Printf("identityが定義されていません（%s）\n", config.IdentitiesPath())
+			return
+		}
+
+		fmt.Println("identity一覧:")
+		for _, name := range identities.Names() {
+			identity, _, err := identities.Lookup(name)
+			if err != nil {
+				fmt.Printf("%s: %v\n", name, err)
+				continue
+			}
+			fmt.Printf("%s: %s <%s> (%s, owner: %s)\n", name, identity.Name, identity.Email, identity.SSHHost, identity.Owner)
+		}
+	},
+}
+
+func init() {
+	identityCmd.AddCommand(identityLsCmd)
+}
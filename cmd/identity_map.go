@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mei/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var identityMapCmd = &cobra.Command{
+	Use:   "map <dir> <identity>",
+	Short: "ディレクトリ配下のリポジトリでidentityを使うようにincludeIfを~/.gitconfigに書き込みます",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, name := args[0], args[1]
+
+		identities, err := config.LoadIdentities()
+		if err != nil {
+			return err
+		}
+		identity, ok, err := identities.Lookup(name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("identity %s は %s に定義されていません", name, config.IdentitiesPath())
+		}
+
+		gitdir, err := includeGitdir(dir)
+		if err != nil {
+			return err
+		}
+
+		includePath, err := writeIdentityInclude(name, identity)
+		if err != nil {
+			return err
+		}
+
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+		}
+		gitconfigPath := filepath.Join(homeDir, ".gitconfig")
+
+		// ディレクトリごとに1つのブロックにし、同じディレクトリを再度mapした場合は置き換える
+		content := fmt.Sprintf("[includeIf \"gitdir:%s\"]\n\tpath = %s\n", gitdir, includePath)
+		blockManager := config.NewBlockManager("mei:gitdir:"+gitdir, content, "#")
+		if err := blockManager.UpdateFile(gitconfigPath); err != nil {
+			return fmt.Errorf("~/.gitconfigの更新に失敗しました: %w", err)
+		}
+
+		fmt.Printf("%s 配下のリポジトリでidentity %s（%s <%s>）を使用します\n", gitdir, name, identity.Name, identity.Email)
+		return nil
+	},
+}
+
+// includeGitdir はincludeIfのgitdir:に指定するパターンを返します
+// ホームディレクトリ配下は~/で表し、配下のすべてのリポジトリに一致するように末尾に/を付けます
+func includeGitdir(dir string) (string, error) {
+	dir, err := filepath.Abs(expandHome(dir))
+	if err != nil {
+		return "", err
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+	}
+	if rel, err := filepath.Rel(homeDir, dir); err == nil && containsPath(homeDir, dir) {
+		dir = "~/" + filepath.ToSlash(rel)
+	}
+	return strings.TrimSuffix(filepath.ToSlash(dir), "/") + "/", nil
+}
+
+// writeIdentityInclude はidentityの設定をincludeIfから読み込むgit configファイルに書き出し、そのパスを返します
+// ファイルは毎回作り直すため、identities.ymlから削除した設定も残りません
+func writeIdentityInclude(name string, identity config.Identity) (string, error) {
+	path := config.IdentityIncludePath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("ディレクトリの作成に失敗しました: %w", err)
+	}
+
+	settings, err := identityGitConfig(identity)
+	if err != nil {
+		return "", err
+	}
+	if err := updateAllowedSigners(name, identity, nil); err != nil {
+		fmt.Printf("警告: %v\n", err)
+	}
+
+	// 同じディレクトリの一時ファイルに書き込んでから置き換え、読み込み中のgitに書きかけの設定を見せない
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".mei-tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("一時ファイルの作成に失敗しました: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	// 失敗した場合に一時ファイルを残さない（置き換えた後は何もしない）
+	defer os.Remove(tmpPath)
+
+	for _, setting := range settings {
+		if err := runGitCommand("", "config", "--file", tmpPath, setting[0], setting[1]); err != nil {
+			return "", fmt.Errorf("%sの書き込みに失敗しました: %w", setting[0], err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", fmt.Errorf("%sの書き込みに失敗しました: %w", path, err)
+	}
+	return path, nil
+}
+
+// refreshIdentityIncludes はmei identity mapで作成したincludeファイルをidentities.ymlの内容で作り直します
+// trackが指定されていれば、書き込む前にスナップショットへ記録します
+func refreshIdentityIncludes(track func(path string) error) error {
+	identities, err := config.LoadIdentities()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range identities.Names() {
+		path := config.IdentityIncludePath(name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		identity, _, err := identities.Lookup(name)
+		if err != nil {
+			fmt.Printf("警告: %v\n", err)
+			continue
+		}
+		if track != nil {
+			if err := track(path); err != nil {
+				return err
+			}
+		}
+		if _, err := writeIdentityInclude(name, identity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	identityCmd.AddCommand(identityMapCmd)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mei/internal/config"
+)
+
+func TestWriteIdentityInclude(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("gitがインストールされていません")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	identity := config.Identity{Name: "Alice", Email: "alice@example.com", Host: "github.com", SSHHost: "github.com-work"}
+	path, err := writeIdentityInclude("work", identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"name = Alice", "email = alice@example.com"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s に %q がありません:\n%s", path, want, content)
+		}
+	}
+	if strings.Contains(string(content), "insteadOf") || strings.Contains(string(content), "insteadof") {
+		t.Errorf("%s にURLの書き換えが含まれています:\n%s", path, content)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".mei-tmp") {
+			t.Errorf("一時ファイルが残っています: %s", entry.Name())
+		}
+	}
+}
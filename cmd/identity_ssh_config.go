@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"mei/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var identitySSHConfigCmd = &cobra.Command{
+	Use:   "ssh-config",
+	Short: "identityごとのSSHホストエイリアスを~/.ssh/configに書き込みます",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateSSHConfig(nil)
+	},
+}
+
+// updateSSHConfig はidentityごとにHostブロックを~/.ssh/configへ書き込みます
+// identities.ymlから削除されたidentityなど、不要になったブロックは取り除きます
+// trackが指定されていれば、書き込む前にスナップショットへ記録します
+func updateSSHConfig(track func(path string) error) error {
+	identities, err := config.LoadIdentities()
+	if err != nil {
+		return err
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+	}
+	sshDir := filepath.Join(homeDir, ".ssh")
+	sshConfigPath := filepath.Join(sshDir, "config")
+
+	current, err := os.ReadFile(sshConfigPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("~/.ssh/configの読み込みに失敗しました: %w", err)
+	}
+	content := string(current)
+
+	// 残すブロックのidentity名（設定の誤りで作成できなかったものは以前のブロックを残す）
+	keep := make(map[string]bool)
+	for _, name := range identities.Names() {
+		identity, _, err := identities.Lookup(name)
+		if err != nil {
+			fmt.Printf("警告: %v\n", err)
+			keep[name] = true
+			continue
+		}
+
+		// エイリアスを使わない場合はブロックが不要
+		if identity.SSHHost == identity.Host {
+			continue
+		}
+		if identity.SSHKey == "" {
+			fmt.Printf("警告: identity %s にssh_keyが指定されていないため、%s のHostブロックを作成しません\n", name, identity.SSHHost)
+			continue
+		}
+		if _, err := os.Stat(expandHome(identity.SSHKey)); err != nil {
+			fmt.Printf("警告: identity %s の鍵ファイルが見つかりません: %s\n", name, identity.SSHKey)
+		}
+
+		keep[name] = true
+		content = applySSHBlock(content, config.NewBlockManager(sshBlockPrefix+name, sshHostBlock(identity), "#"))
+	}
+
+	for _, name := range sshBlockNames(content) {
+		if !keep[name] {
+			content = config.NewBlockManager(sshBlockPrefix+name, "", "#").Remove(content)
+		}
+	}
+
+	if content == string(current) {
+		return nil
+	}
+	if err := os.MkdirAll(sshDir, 0700); err != nil {
+		return fmt.Errorf(".sshディレクトリの作成に失敗しました: %w", err)
+	}
+	if track != nil {
+		if err := track(sshConfigPath); err != nil {
+			return err
+		}
+	}
+	if err := config.WriteFileAtomic(sshConfigPath, []byte(content), 0600); err != nil {
+		return fmt.Errorf("~/.ssh/configの更新に失敗しました: %w", err)
+	}
+	fmt.Printf("%s を更新しました\n", sshConfigPath)
+	return nil
+}
+
+// sshBlockPrefix は~/.ssh/configに書き込むブロックのラベルの接頭辞です（後ろにidentity名が続きます）
+const sshBlockPrefix = "mei:"
+
+// sshBlockBegin は~/.ssh/configにあるmeiのブロックの開始行に一致します
+var sshBlockBegin = regexp.MustCompile(`(?m)^# BEGIN:` + regexp.QuoteMeta(sshBlockPrefix) + `(.+)$`)
+
+// sshCatchAll は~/.ssh/configで「Host *」や「Match」のスタンザの開始行に一致します
+// sshは最初に見つかった値を使うため、これより後に書いたHostブロックの設定は上書きされます
+var sshCatchAll = regexp.MustCompile(`(?mi)^[ \t]*(Host[ \t=]+\*[ \t]*$|Match[ \t=])`)
+
+// applySSHBlock はHostブロックを反映した内容を返します
+// 新しいブロックや「Host *」「Match」より後にあるブロックは、それらのスタンザの前に置きます（ない場合は末尾に追加します）
+func applySSHBlock(content string, blockManager *config.BlockManager) string {
+	if blockManager.Contains(content) {
+		applied := blockManager.Apply(content)
+		catchAll := sshCatchAll.FindStringIndex(applied)
+		if catchAll == nil || strings.Index(applied, blockManager.Format()) < catchAll[0] {
+			return applied
+		}
+		content = blockManager.Remove(content)
+	}
+
+	catchAll := sshCatchAll.FindStringIndex(content)
+	if catchAll == nil {
+		return blockManager.Apply(content)
+	}
+	return content[:catchAll[0]] + blockManager.Format() + "\n" + content[catchAll[0]:]
+}
+
+// sshBlockNames は内容に含まれるmeiのブロックのidentity名を返します
+func sshBlockNames(content string) []string {
+	var names []string
+	for _, match := range sshBlockBegin.FindAllStringSubmatch(content, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// sshHostBlock はidentityのホストエイリアスを実際のホストに対応付けるHostブロックを返します
+func sshHostBlock(identity config.Identity) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Host %s\n", identity.SSHHost)
+	fmt.Fprintf(&buf, "  HostName %s\n", identity.Host)
+	fmt.Fprintf(&buf, "  User git\n")
+	fmt.Fprintf(&buf, "  IdentityFile %s\n", identity.SSHKey)
+	fmt.Fprintf(&buf, "  IdentitiesOnly yes\n")
+	return buf.String()
+}
+
+// expandHome は先頭の~/をホームディレクトリに展開します
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(os.Getenv("HOME"), rest)
+	}
+	return path
+}
+
+func init() {
+	identityCmd.AddCommand(identitySSHConfigCmd)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mei/internal/config"
+)
+
+func TestUpdateSSHConfig(t *testing.T) {
+	const identities = `identities:
+  work:
+    email: alice@example.com
+    ssh_host: github.com-work
+    ssh_key: ~/.ssh/id_work
+  plain:
+    email: bob@example.com
+  broken:
+    ssh_host: github.com-broken
+`
+	userHost := "Host example\n  HostName example.com\n"
+	hostAll := "Host *\n  IdentityFile ~/.ssh/id_default\n"
+	match := "Match host *.corp.example.com\n  User me\n"
+	staleBlock := config.NewBlockManager("mei:old", "Host github.com-old\n  HostName github.com\n", "#").Format()
+	brokenBlock := config.NewBlockManager("mei:broken", "Host github.com-broken\n  HostName github.com\n", "#").Format()
+	workBlock := config.NewBlockManager("mei:work", sshHostBlock(config.Identity{
+		SSHHost: "github.com-work",
+		Host:    "github.com",
+		SSHKey:  "~/.ssh/id_work",
+	}), "#").Format()
+
+	tests := []struct {
+		name        string
+		identities  string
+		existing    *string // nilの場合はファイルがない
+		want        *string // nilの場合はファイルを作成しない
+		wantChanged bool
+	}{
+		{
+			name:        "adds blocks",
+			identities:  identities,
+			want:        ptr(workBlock),
+			wantChanged: true,
+		},
+		{
+			name:       "unchanged",
+			identities: identities,
+			existing:   ptr(userHost + "\n" + workBlock),
+			want:       ptr(userHost + "\n" + workBlock),
+		},
+		{
+			name:        "removes stale block",
+			identities:  identities,
+			existing:    ptr(userHost + "\n" + workBlock + staleBlock),
+			want:        ptr(userHost + "\n" + workBlock),
+			wantChanged: true,
+		},
+		{
+			name:       "keeps block of misconfigured identity",
+			identities: identities,
+			existing:   ptr(userHost + "\n" + brokenBlock + workBlock),
+			want:       ptr(userHost + "\n" + brokenBlock + workBlock),
+		},
+		{
+			name:        "inserts before Host *",
+			identities:  identities,
+			existing:    ptr(userHost + "\n" + hostAll),
+			want:        ptr(userHost + "\n" + workBlock + "\n" + hostAll),
+			wantChanged: true,
+		},
+		{
+			name:        "inserts before Match",
+			identities:  identities,
+			existing:    ptr(match),
+			want:        ptr(workBlock + "\n" + match),
+			wantChanged: true,
+		},
+		{
+			name:       "keeps block before Host *",
+			identities: identities,
+			existing:   ptr(userHost + "\n" + workBlock + "\n" + hostAll),
+			want:       ptr(userHost + "\n" + workBlock + "\n" + hostAll),
+		},
+		{
+			name:        "moves block after Host * before it",
+			identities:  identities,
+			existing:    ptr(userHost + "\n" + hostAll + "\n" + workBlock),
+			want:        ptr(userHost + "\n" + workBlock + "\n" + hostAll + "\n"),
+			wantChanged: true,
+		},
+		{
+			name:        "removes blocks when no identities remain",
+			existing:    ptr(userHost + workBlock),
+			want:        ptr(userHost),
+			wantChanged: true,
+		},
+		{
+			name:       "no identities and no file",
+			identities: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			if tt.identities != "" {
+				writeTestFile(t, filepath.Join(home, ".mei", "identities.yml"), tt.identities)
+			}
+			path := filepath.Join(home, ".ssh", "config")
+			if tt.existing != nil {
+				writeTestFile(t, path, *tt.existing)
+			}
+
+			var tracked []string
+			track := func(path string) error {
+				tracked = append(tracked, path)
+				return nil
+			}
+			if err := updateSSHConfig(track); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			switch {
+			case tt.want == nil:
+				if !os.IsNotExist(err) {
+					t.Errorf("%s を作成しました: %q", path, data)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case string(data) != *tt.want:
+				t.Errorf("~/.ssh/config:\n%s\nwant:\n%s", data, *tt.want)
+			}
+			if changed := len(tracked) > 0; changed != tt.wantChanged {
+				t.Errorf("変更の記録 = %v; want %v", tracked, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestUpdateSSHConfigNewFileMode(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeTestFile(t, filepath.Join(home, ".mei", "identities.yml"), "identities:\n  work:\n    email: a@example.com\n    ssh_host: github.com-work\n    ssh_key: ~/.ssh/id_work\n")
+
+	if err := updateSSHConfig(nil); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(home, ".ssh", "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		t.Errorf("~/.ssh/configの権限が %v です", perm)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
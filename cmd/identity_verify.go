@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"mei/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var identityVerifyCmd = &cobra.Command{
+	Use:   "verify [name]",
+	Short: "一時リポジトリでテスト署名を行い、identityの署名設定を確認します",
+	Args:  cobra.MaximumNArgs(1),
+	// 署名の失敗は使い方の誤りではないため使い方を表示しない
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		identities, err := config.LoadIdentities()
+		if err != nil {
+			return err
+		}
+
+		// 名前が指定されていなければ署名鍵を持つすべてのidentityを確認する
+		names := args
+		if len(names) == 0 {
+			for _, name := range identities.Names() {
+				if identities.Identities[name].SigningKey != "" {
+					names = append(names, name)
+				}
+			}
+			if len(names) == 0 {
+				fmt.Println("signing_keyが指定されているidentityはありません")
+				return nil
+			}
+		}
+
+		failed := 0
+		for _, name := range names {
+			identity, ok, err := identities.Lookup(name)
+			if err == nil && !ok {
+				err = fmt.Errorf("identity %s は %s に定義されていません", name, config.IdentitiesPath())
+			}
+			if err == nil {
+				err = verifySigning(identity)
+			}
+			if err != nil {
+				fmt.Printf("✗ %s: %v\n", name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✓ %s: %s署名の作成と検証に成功しました\n", name, identity.SigningFormat)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d件のidentityで署名の確認に失敗しました", failed)
+		}
+		return nil
+	},
+}
+
+// verifySigning は一時リポジトリにidentityの署名設定でコミットを作成し、その署名を検証します
+// SSH署名の検証には一時ディレクトリに作成したallowed signersファイルを使い、~/.ssh/allowed_signersは変更しません
+func verifySigning(identity config.Identity) error {
+	if identity.SigningKey == "" {
+		return fmt.Errorf("signing_keyが指定されていません")
+	}
+	signing, err := signingConfig(identity)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "mei-verify-")
+	if err != nil {
+		return fmt.Errorf("一時ディレクトリの作成に失敗しました: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if identity.SigningFormat == "ssh" {
+		line, err := allowedSignersLine(identity)
+		if err != nil {
+			return err
+		}
+		signersPath := filepath.Join(dir, "allowed_signers")
+		if err := os.WriteFile(signersPath, []byte(line), 0600); err != nil {
+			return fmt.Errorf("allowed signersファイルの作成に失敗しました: %w", err)
+		}
+		for i, setting := range signing {
+			if setting[0] == "gpg.ssh.allowedSignersFile" {
+				signing[i][1] = signersPath
+			}
+		}
+	}
+
+	// allowed signersファイルがリポジトリの中にないように、サブディレクトリに作成する
+	repoDir := filepath.Join(dir, "repo")
+	if err := runGitCommand(dir, "init", "--quiet", repoDir); err != nil {
+		return err
+	}
+	commands := [][]string{
+		{"config", "--local", "user.name", identity.Name},
+		{"config", "--local", "user.email", identity.Email},
+	}
+	for _, setting := range signing {
+		commands = append(commands, []string{"config", "--local", setting[0], setting[1]})
+	}
+	commands = append(commands,
+		[]string{"commit", "--quiet", "--allow-empty", "--gpg-sign", "--message", "mei identity verify"},
+		[]string{"verify-commit", "HEAD"},
+	)
+
+	for _, args := range commands {
+		if err := runGitCommand(repoDir, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	identityCmd.AddCommand(identityVerifyCmd)
+}
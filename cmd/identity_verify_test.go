@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"mei/internal/config"
+)
+
+func TestVerifySigningIsReadOnly(t *testing.T) {
+	for _, tool := range []string{"git", "ssh-keygen"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%sがインストールされていません", tool)
+		}
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	// ユーザーのgit設定の影響を受けないようにする
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	keyPath := filepath.Join(home, ".ssh", "id_sign")
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", keyPath).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v: %s", err, output)
+	}
+
+	// 秘密鍵のパスを指定した場合もSSH署名と判定する
+	writeTestFile(t, filepath.Join(home, ".mei", "identities.yml"), `identities:
+  work:
+    name: Alice
+    email: alice@example.com
+    signing_key: ~/.ssh/id_sign
+`)
+	identities, err := config.LoadIdentities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity, _, err := identities.Lookup("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.SigningFormat != "ssh" {
+		t.Fatalf("signing_format = %s; want ssh", identity.SigningFormat)
+	}
+
+	if err := verifySigning(identity); err != nil {
+		t.Fatalf("verifySigning: %v", err)
+	}
+	signersPath := filepath.Join(home, ".ssh", "allowed_signers")
+	if _, err := os.Stat(signersPath); !os.IsNotExist(err) {
+		t.Errorf("verifySigningが %s を作成しました", signersPath)
+	}
+}
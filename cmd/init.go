@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultsFS は~/.meiの初期内容です
+//
+//go:embed templates/.cursor templates/git
+var defaultsFS embed.FS
+
+// defaultCursorFS は~/.mei/cursorの初期内容を返します
+func defaultCursorFS() fs.FS {
+	sub, err := fs.Sub(defaultsFS, "templates/.cursor")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// defaultExclude は~/.mei/git/excludeの初期内容を返します
+func defaultExclude() []byte {
+	data, err := defaultsFS.ReadFile("templates/git/exclude")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "~/.meiディレクトリを初期化します",
+	Long:  "~/.meiディレクトリに組み込みのデフォルト（cursor、git/exclude、github、env）を作成します。既存のファイルは上書きしません。",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+		}
+		meiDir := filepath.Join(homeDir, ".mei")
+
+		var created []string
+
+		// cursorディレクトリを作成
+		cursorCreated, err := writeDefaultFS(defaultCursorFS(), filepath.Join(meiDir, "cursor"))
+		if err != nil {
+			return fmt.Errorf("cursorディレクトリの作成に失敗しました: %w", err)
+		}
+		created = append(created, cursorCreated...)
+
+		// git/excludeファイルを作成
+		excludePath := filepath.Join(meiDir, "git", "exclude")
+		if ok, err := writeDefaultFile(excludePath, defaultExclude()); err != nil {
+			return fmt.Errorf("excludeファイルの作成に失敗しました: %w", err)
+		} else if ok {
+			created = append(created, excludePath)
+		}
+
+		// github、envディレクトリを作成
+		for _, name := range []string{"github", "env"} {
+			dir := filepath.Join(meiDir, name)
+			if ok, err := makeDefaultDir(dir); err != nil {
+				return fmt.Errorf("%sディレクトリの作成に失敗しました: %w", name, err)
+			} else if ok {
+				created = append(created, dir)
+			}
+		}
+
+		if len(created) == 0 {
+			fmt.Println("~/.mei は既に初期化されています")
+			return nil
+		}
+
+		fmt.Println("以下を作成しました:")
+		for _, path := range created {
+			fmt.Printf("  %s\n", path)
+		}
+		return nil
+	},
+}
+
+// writeDefaultFS はfsysの内容をdstに書き出し、作成したパスを返します
+// 既存のファイルは上書きしません
+func writeDefaultFS(fsys fs.FS, dst string) ([]string, error) {
+	var created []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(dst, filepath.FromSlash(path))
+
+		if d.IsDir() {
+			ok, err := makeDefaultDir(destPath)
+			if ok {
+				created = append(created, destPath)
+			}
+			return err
+		}
+
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		ok, err := writeDefaultFile(destPath, data)
+		if ok {
+			created = append(created, destPath)
+		}
+		return err
+	})
+	return created, err
+}
+
+// writeDefaultFile はファイルが存在しない場合のみ作成します
+func writeDefaultFile(path string, data []byte) (bool, error) {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// makeDefaultDir はディレクトリが存在しない場合のみ作成します
+func makeDefaultDir(path string) (bool, error) {
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return false, err
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
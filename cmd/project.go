@@ -1,7 +1,15 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 // projectCmd はプロジェクト関連のコマンドを表します
@@ -13,4 +21,110 @@ var projectCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(projectCmd)
-} 
\ No newline at end of file
+}
+
+// loadProjects は~/.mei/projects.ymlから登録されているプロジェクトを読み込みます
+// ファイルが存在しない場合は空のリストを返します
+func loadProjects() ([]Project, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("ホームディレクトリを取得できませんでした: %w", err)
+	}
+	projectsFile := filepath.Join(homeDir, ".mei", "projects.yml")
+
+	data, err := os.ReadFile(projectsFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("プロジェクトファイルを読み込めませんでした: %w", err)
+	}
+
+	var projects []Project
+	// 古い形式（文字列の配列）からの移行をサポート
+	var oldProjects []string
+	err = yaml.Unmarshal(data, &oldProjects)
+	if err == nil && len(oldProjects) > 0 {
+		// 古い形式から新しい形式に変換
+		for _, path := range oldProjects {
+			projects = append(projects, Project{
+				Name:      filepath.Base(path),
+				Path:      path,
+				CreatedAt: time.Now(),
+			})
+		}
+		return projects, nil
+	}
+
+	// 新しい形式として読み込み
+	if err := yaml.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("YAMLの解析に失敗しました: %w", err)
+	}
+	return projects, nil
+}
+
+// registerProject はプロジェクトを~/.mei/projects.ymlに追加します
+// 同じパスのプロジェクトが既に登録されている場合はエラーを返します
+func registerProject(project Project) error {
+	projects, err := loadProjects()
+	if err != nil {
+		return err
+	}
+	for _, registered := range projects {
+		if registered.Path == project.Path {
+			return fmt.Errorf("このディレクトリは既に登録されています: %s", project.Path)
+		}
+	}
+	projects = append(projects, project)
+
+	meiDir, err := meiHomeDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(meiDir, 0755); err != nil {
+		return fmt.Errorf(".meiディレクトリを作成できませんでした: %w", err)
+	}
+
+	data, err := yaml.Marshal(projects)
+	if err != nil {
+		return fmt.Errorf("YAMLの生成に失敗しました: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(meiDir, "projects.yml"), data, 0644); err != nil {
+		return fmt.Errorf("プロジェクトファイルの保存に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// filterProjectsByTag はタグが付いたプロジェクトだけを返します（タグが空の場合はすべてを返します）
+func filterProjectsByTag(projects []Project, tag string) []Project {
+	if tag == "" {
+		return projects
+	}
+	var filtered []Project
+	for _, project := range projects {
+		if slices.Contains(project.Tags, tag) {
+			filtered = append(filtered, project)
+		}
+	}
+	return filtered
+}
+
+// forEachProjectParallel は最大jobs個ずつ並行してプロジェクトごとにfnを実行し、すべて終わるまで待ちます
+// fnにはプロジェクトの添字も渡すため、結果を登録順に並べられます
+func forEachProjectParallel(projects []Project, jobs int, fn func(i int, project Project)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for i, project := range projects {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i, project)
+		}()
+	}
+	wg.Wait()
+}
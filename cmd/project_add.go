@@ -7,16 +7,20 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 // Project はプロジェクト情報を表す構造体
 type Project struct {
-	Name      string    `yaml:"name"`       // プロジェクト名（デフォルトはディレクトリ名）
-	Path      string    `yaml:"path"`       // プロジェクトのパス
-	GitUser   string    `yaml:"git_user,omitempty"` // Gitユーザー名（省略可能）
-	EnvKeys   []string  `yaml:"env_keys,omitempty"` // 環境変数キーのリスト（省略可能）
-	CreatedAt time.Time `yaml:"created_at"` // 登録日時
+	Name         string    `yaml:"name"`                    // プロジェクト名（デフォルトはディレクトリ名）
+	Path         string    `yaml:"path"`                    // プロジェクトのパス
+	GitUser      string    `yaml:"git_user,omitempty"`      // Gitユーザー名（省略可能）
+	EnvKeys      []string  `yaml:"env_keys,omitempty"`      // 環境変数キーのリスト（省略可能）
+	Tags         []string  `yaml:"tags,omitempty"`          // タグのリスト（省略可能）
+	Links        []string  `yaml:"links,omitempty"`         // コピーせずにシンボリックリンクで同期するパスのリスト（例: .cursor/rules）
+	PreSync      []string  `yaml:"pre_sync,omitempty"`      // 同期前にプロジェクトディレクトリで実行するコマンド（省略可能）
+	PostSync     []string  `yaml:"post_sync,omitempty"`     // 同期後にプロジェクトディレクトリで実行するコマンド（省略可能）
+	IdentityHook bool      `yaml:"identity_hook,omitempty"` // pre-commitフックでidentityを確認するか（省略可能）
+	CreatedAt    time.Time `yaml:"created_at"`              // 登録日時
 }
 
 var addCmd = &cobra.Command{
@@ -30,56 +34,12 @@ var addCmd = &cobra.Command{
 			return
 		}
 
-		// ~/.mei/projects.ymlのパスを作成
-		homeDir, err := os.UserHomeDir()
+		// 既に登録されているか確認
+		projects, err := loadProjects()
 		if err != nil {
-			fmt.Println("ホームディレクトリを取得できませんでした:", err)
+			fmt.Println(err)
 			return
 		}
-		meiDir := filepath.Join(homeDir, ".mei")
-		projectsFile := filepath.Join(meiDir, "projects.yml")
-
-		// .meiディレクトリが存在しない場合は作成
-		if _, err := os.Stat(meiDir); os.IsNotExist(err) {
-			err = os.MkdirAll(meiDir, 0755)
-			if err != nil {
-				fmt.Println(".meiディレクトリを作成できませんでした:", err)
-				return
-			}
-		}
-
-		// 既存のプロジェクトリストを読み込む
-		var projects []Project
-		if _, err := os.Stat(projectsFile); !os.IsNotExist(err) {
-			data, err := os.ReadFile(projectsFile)
-			if err != nil {
-				fmt.Println("プロジェクトファイルを読み込めませんでした:", err)
-				return
-			}
-
-			// 古い形式（文字列の配列）からの移行をサポート
-			var oldProjects []string
-			err = yaml.Unmarshal(data, &oldProjects)
-			if err == nil && len(oldProjects) > 0 {
-				// 古い形式から新しい形式に変換
-				for _, path := range oldProjects {
-					projects = append(projects, Project{
-						Name:      filepath.Base(path),
-						Path:      path,
-						CreatedAt: time.Now(),
-					})
-				}
-			} else {
-				// 新しい形式として読み込み
-				err = yaml.Unmarshal(data, &projects)
-				if err != nil {
-					fmt.Println("YAMLの解析に失敗しました:", err)
-					return
-				}
-			}
-		}
-
-		// 既に登録されているか確認
 		for _, project := range projects {
 			if project.Path == currentDir {
 				fmt.Println("このディレクトリは既に登録されています")
@@ -93,25 +53,33 @@ var addCmd = &cobra.Command{
 			Path:      currentDir,
 			CreatedAt: time.Now(),
 		}
-		
+
 		// git-userオプションが指定されていれば設定
 		gitUser, err := cmd.Flags().GetString("git-user")
 		if err == nil && gitUser != "" {
 			newProject.GitUser = gitUser
 		}
-		
-		projects = append(projects, newProject)
 
-		// YAMLとして保存
-		data, err := yaml.Marshal(projects)
-		if err != nil {
-			fmt.Println("YAMLの生成に失敗しました:", err)
-			return
+		// tagオプションが指定されていれば設定
+		tags, err := cmd.Flags().GetStringSlice("tag")
+		if err == nil && len(tags) > 0 {
+			newProject.Tags = tags
 		}
 
-		err = os.WriteFile(projectsFile, data, 0644)
-		if err != nil {
-			fmt.Println("プロジェクトファイルの保存に失敗しました:", err)
+		// linkオプションが指定されていれば設定
+		links, err := cmd.Flags().GetStringSlice("link")
+		if err == nil && len(links) > 0 {
+			newProject.Links = links
+		}
+
+		// identity-hookオプションが指定されていれば設定
+		identityHook, err := cmd.Flags().GetBool("identity-hook")
+		if err == nil && identityHook {
+			newProject.IdentityHook = true
+		}
+
+		if err := registerProject(newProject); err != nil {
+			fmt.Println(err)
 			return
 		}
 
@@ -123,4 +91,7 @@ func init() {
 	// rootCmd.AddCommand(addCmd) // 古い登録方法
 	projectCmd.AddCommand(addCmd) // projectコマンドのサブコマンドとして登録
 	addCmd.Flags().String("git-user", "", "プロジェクト用のGitユーザー名を指定します")
-} 
\ No newline at end of file
+	addCmd.Flags().StringSlice("tag", nil, "プロジェクトのタグを指定します（複数指定可）")
+	addCmd.Flags().StringSlice("link", nil, "コピーせずにシンボリックリンクで同期するパスを指定します（例: .cursor/rules）")
+	addCmd.Flags().Bool("identity-hook", false, "コミット前にidentityを確認するpre-commitフックを同期時にインストールします")
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"mei/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var projectRestoreCmd = &cobra.Command{
+	Use:   "restore <run-id> [project]",
+	Short: "同期で変更されたファイルを同期前の状態に戻します",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshot, err := config.LoadSnapshot(args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// プロジェクトが指定されていればそのプロジェクトだけを戻す
+		project := ""
+		if len(args) > 1 {
+			project = args[1]
+			if !slices.Contains(snapshot.Projects(), project) {
+				fmt.Printf("%s にはプロジェクト %s の変更が含まれていません\n", snapshot.ID, project)
+				return
+			}
+		}
+
+		state, err := config.LoadSyncState()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		restored, err := snapshot.Restore(project, state)
+		for _, path := range restored {
+			fmt.Printf("復元しました: %s\n", path)
+		}
+		if err != nil {
+			fmt.Println(err)
+		}
+
+		// 復元したファイルが次回の同期で競合と判定されないように記録を戻す
+		if err := state.Save(); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if err == nil {
+			fmt.Printf("%s の同期前の状態に戻しました\n", snapshot.ID)
+		}
+	},
+}
+
+var projectHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "元に戻せる同期の履歴を表示します",
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshots, err := config.ListSnapshots()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if len(snapshots) == 0 {
+			fmt.Println("同期の履歴はありません")
+			return
+		}
+
+		fmt.Println("同期の履歴:")
+		for _, snapshot := range snapshots {
+			fmt.Printf("%s  %s  %d件  %v\n",
+				snapshot.ID,
+				snapshot.CreatedAt.Format("2006-01-02 15:04:05"),
+				len(snapshot.Entries),
+				snapshot.Projects())
+		}
+	},
+}
+
+func init() {
+	projectCmd.AddCommand(projectRestoreCmd)
+	projectCmd.AddCommand(projectHistoryCmd)
+}
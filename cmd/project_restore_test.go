@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mei/internal/config"
+)
+
+// readTree はroot配下のディレクトリ、ファイル（権限と内容）、シンボリックリンクを相対パスをキーにして返します
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	tree := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			tree[rel] = "link " + target
+		case d.IsDir():
+			tree[rel] = "dir"
+		default:
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			tree[rel] = fmt.Sprintf("%v %s", info.Mode().Perm(), content)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+// diffTrees は2つのreadTreeの結果の違いを表示用に返します
+func diffTrees(want, got map[string]string) []string {
+	var diffs []string
+	for path, content := range want {
+		if got[path] != content {
+			diffs = append(diffs, fmt.Sprintf("%s: %q -> %q", path, content, got[path]))
+		}
+	}
+	for path, content := range got {
+		if _, ok := want[path]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: 追加されています（%q）", path, content))
+		}
+	}
+	return diffs
+}
+
+func TestSyncRestoreRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("gitがインストールされていません")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	meiDir := filepath.Join(home, ".mei")
+	writeTestFile(t, filepath.Join(meiDir, "identities.yml"), testIdentities)
+	writeTestFile(t, filepath.Join(meiDir, "cursor", "rules", "a.mdc"), "rule\n")
+	writeTestFile(t, filepath.Join(meiDir, "git", "exclude"), ".env\n")
+	// シェル以外のフックは.git/hooks/mei/に書き出される
+	writeTestFile(t, filepath.Join(meiDir, "git", "hooks", "pre-commit"), "#!/usr/bin/env python3\nprint('hi')\n")
+
+	mono := filepath.Join(t.TempDir(), "mono")
+	if output, err := exec.Command("git", "init", "--quiet", mono).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+	for _, dir := range []string{"api", "web"} {
+		writeTestFile(t, filepath.Join(mono, dir, "README.md"), dir+"\n")
+	}
+	// 同じリポジトリのサブプロジェクトに異なるGitユーザーを指定し、.git/configを両方が変更するようにする
+	projects := []Project{
+		{Name: "api", Path: filepath.Join(mono, "api"), GitUser: "work", IdentityHook: true},
+		{Name: "web", Path: filepath.Join(mono, "web"), GitUser: "personal"},
+	}
+
+	sync := func(projects ...Project) map[string]string {
+		t.Helper()
+		state, err := config.LoadSyncState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		syncer := newFileSyncer(state, &config.SyncManifest{}, conflictFail, symlinkPreserve)
+		syncer.projects = newProjectIndex(projects)
+		captureStdout(t, func() {
+			for _, project := range projects {
+				if !syncProject(project, syncer, defaultHookTimeout, nil) {
+					t.Errorf("%s の同期に失敗しました", project.Name)
+				}
+			}
+		})
+		if err := state.Save(); err != nil {
+			t.Fatal(err)
+		}
+		snapshot := syncer.snapshot
+		captureStdout(t, func() {
+			if err := syncer.saveSnapshot(); err != nil {
+				t.Fatal(err)
+			}
+		})
+		return map[string]string{"id": snapshot.ID}
+	}
+	restore := func(id, project string) {
+		t.Helper()
+		snapshot, err := config.LoadSnapshot(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, err := config.LoadSyncState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := snapshot.Restore(project, state); err != nil {
+			t.Fatal(err)
+		}
+		if err := state.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	userEmail := func() string {
+		t.Helper()
+		output, _ := exec.Command("git", "config", "--file", filepath.Join(mono, ".git", "config"), "user.email").Output()
+		return strings.TrimSpace(string(output))
+	}
+
+	before := readTree(t, mono)
+	id := sync(projects...)["id"]
+	if id == "" {
+		t.Fatal("スナップショットが保存されませんでした")
+	}
+	for _, path := range []string{"api/.cursor/rules/a.mdc", ".git/hooks/mei/pre-commit", ".git/hooks/pre-commit"} {
+		if _, err := os.Stat(filepath.Join(mono, path)); err != nil {
+			t.Fatalf("同期で %s が作成されていません: %v", path, err)
+		}
+	}
+	if got := userEmail(); got != "personal@gmail.com" {
+		t.Fatalf("同期後のuser.email = %q, want personal@gmail.com", got)
+	}
+
+	// webだけを戻すと、共有している.git/configはapiを同期した後の状態になる
+	restore(id, "web")
+	if got := userEmail(); got != "alice@example.com" {
+		t.Errorf("webを戻した後のuser.email = %q, want alice@example.com", got)
+	}
+	if _, err := os.Stat(filepath.Join(mono, "web", ".cursor")); !os.IsNotExist(err) {
+		t.Errorf("webの.cursorが削除されていません: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mono, "api", ".cursor", "rules", "a.mdc")); err != nil {
+		t.Errorf("apiの.cursorが戻されました: %v", err)
+	}
+
+	// apiも戻すと同期前の状態と一致する
+	restore(id, "api")
+	if diffs := diffTrees(before, readTree(t, mono)); len(diffs) > 0 {
+		t.Errorf("復元後の状態が同期前と異なります:\n%s", strings.Join(diffs, "\n"))
+	}
+}
@@ -1,327 +1,556 @@
 package cmd
 
 import (
-	"embed"
+	"errors"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"mei/internal/config"
+	"mei/internal/gitrepo"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
-	"io/fs"
 )
 
-//go:embed templates/.cursor
-var cursorFS embed.FS
+// syncStep は同期処理の単位です
+type syncStep string
+
+const (
+	stepCursor  syncStep = "cursor"   // .cursorディレクトリのコピー
+	stepExclude syncStep = "exclude"  // .git/info/excludeの更新
+	stepHooks   syncStep = "hooks"    // .git/hooksへのフックの配布
+	stepGithub  syncStep = "github"   // .githubディレクトリのコピー
+	stepGitUser syncStep = "git-user" // Gitユーザー設定の更新
+	stepRemote  syncStep = "remote"   // originのホストの書き換え
+	stepEnv     syncStep = "env"      // .envの更新
+)
+
+// allSyncSteps はすべての同期処理を実行順に並べたものです
+var allSyncSteps = []syncStep{stepCursor, stepExclude, stepHooks, stepGithub, stepGitUser, stepRemote, stepEnv}
+
+// syncSteps は実行する同期処理の集合です（nilはすべてを表します）
+type syncSteps map[syncStep]bool
+
+func (s syncSteps) has(step syncStep) bool {
+	return s == nil || s[step]
+}
+
+// without はstepを除いた同期処理の集合を返します
+func (s syncSteps) without(step syncStep) syncSteps {
+	steps := syncSteps{}
+	for _, candidate := range allSyncSteps {
+		if candidate != step && s.has(candidate) {
+			steps[candidate] = true
+		}
+	}
+	return steps
+}
 
 var projectSyncCmd = &cobra.Command{
 	Use:   "sync",
 	Short: "登録されているプロジェクトに必要なファイルをコピーします",
-	Run: func(cmd *cobra.Command, args []string) {
-		// ~/.mei/projects.ymlのパスを作成
-		homeDir, err := os.UserHomeDir()
+	// 同期の失敗は使い方の誤りではないため使い方を表示しない
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// ~/.mei/projects.ymlからプロジェクトリストを読み込む
+		projects, err := loadProjects()
 		if err != nil {
-			fmt.Println("ホームディレクトリを取得できませんでした:", err)
-			return
+			return err
 		}
-		meiDir := filepath.Join(homeDir, ".mei")
-		projectsFile := filepath.Join(meiDir, "projects.yml")
 
-		// プロジェクトファイルが存在しない場合
-		if _, err := os.Stat(projectsFile); os.IsNotExist(err) {
+		// プロジェクトが登録されていない場合
+		if len(projects) == 0 {
 			fmt.Println("登録されているプロジェクトはありません")
-			return
+			return nil
 		}
 
-		// プロジェクトリストを読み込む
-		data, err := os.ReadFile(projectsFile)
+		// 競合時の振る舞いを取得
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		policy, err := parseConflictPolicy(onConflict)
 		if err != nil {
-			fmt.Println("プロジェクトファイルを読み込めませんでした:", err)
-			return
-		}
-
-		var projects []Project
-		// 古い形式（文字列の配列）からの移行をサポート
-		var oldProjects []string
-		err = yaml.Unmarshal(data, &oldProjects)
-		if err == nil && len(oldProjects) > 0 {
-			// 古い形式から新しい形式に変換
-			for _, path := range oldProjects {
-				projects = append(projects, Project{
-					Name:      filepath.Base(path),
-					Path:      path,
-					GitUser:   "", // 空のGitUser
-					CreatedAt: time.Now(), // nilではなく現在時刻を設定
-				})
-			}
-		} else {
-			// 新しい形式として読み込み
-			err = yaml.Unmarshal(data, &projects)
-			if err != nil {
-				fmt.Println("YAMLの解析に失敗しました:", err)
-				return
-			}
+			return err
 		}
 
-		// プロジェクトが登録されていない場合
-		if len(projects) == 0 {
-			fmt.Println("登録されているプロジェクトはありません")
-			return
+		// 前回の同期状態を読み込む
+		state, err := config.LoadSyncState()
+		if err != nil {
+			return err
+		}
+		// 同期単位ごとの設定を読み込む
+		manifest, err := config.LoadSyncManifest()
+		if err != nil {
+			return err
+		}
+		// 同期元のシンボリックリンクの扱いを取得
+		symlinks, _ := cmd.Flags().GetString("symlinks")
+		symlinkMode, err := parseSymlinkPolicy(symlinks)
+		if err != nil {
+			return err
 		}
+		syncer := newFileSyncer(state, manifest, policy, symlinkMode)
+		syncer.projects = newProjectIndex(projects)
 
-		// ~/.mei/cursor ディレクトリのパス
-		cursorSourceDir := filepath.Join(meiDir, "cursor")
-		
-		// ~/.mei/cursor ディレクトリが存在するか確認
-		if _, err := os.Stat(cursorSourceDir); os.IsNotExist(err) {
-			fmt.Println("~/.mei/cursor ディレクトリが存在しません")
-			return
+		// フックのタイムアウトを取得
+		hookTimeout, _ := cmd.Flags().GetDuration("hook-timeout")
+
+		// --no-remoteが指定されていればoriginを書き換えない
+		var steps syncSteps
+		if noRemote, _ := cmd.Flags().GetBool("no-remote"); noRemote {
+			steps = steps.without(stepRemote)
+		}
+
+		// identityごとのSSHホストエイリアスを~/.ssh/configに反映
+		if err := updateSSHConfig(syncer.track); err != nil {
+			fmt.Printf("警告: %v\n", err)
+		}
+		// mei identity mapで作成したincludeファイルをidentityの変更に追従させる
+		if err := refreshIdentityIncludes(syncer.track); err != nil {
+			fmt.Printf("警告: %v\n", err)
 		}
 
 		// 各プロジェクトに対して処理を実行
+		var failed []string
 		for _, project := range projects {
-			fmt.Printf("プロジェクト %s を同期中...\n", project.Name)
-			
-			// .cursor ディレクトリをコピー
-			targetDir := filepath.Join(project.Path, ".cursor")
-			err := copyDir(cursorSourceDir, targetDir)
-			if err != nil {
-				fmt.Printf("%s へのcursorディレクトリのコピーに失敗しました: %v\n", project.Name, err)
-				continue
+			if !syncProject(project, syncer, hookTimeout, steps) {
+				failed = append(failed, project.Name)
 			}
-			
-			// repo setup相当の処理を実行
-			if err := setupRepo(project); err != nil {
-				fmt.Printf("%s のrepo setup処理に失敗しました: %v\n", project.Name, err)
-				continue
-			}
-			
-			fmt.Printf("%s の同期が完了しました\n", project.Name)
+		}
+
+		// 書き込んだ内容を次回の競合検出のために保存
+		if err := state.Save(); err != nil {
+			return err
+		}
+
+		// 変更前の状態を保存
+		if err := syncer.saveSnapshot(); err != nil {
+			return err
 		}
 		
-		fmt.Println("すべてのプロジェクトの同期が完了しました")
+		if len(failed) == 0 {
+			fmt.Println("すべてのプロジェクトの同期が完了しました")
+		}
+
+		// watchオプションが指定されていれば同期元の変更を監視する
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			interval, _ := cmd.Flags().GetDuration("watch-interval")
+			if err := watchSources(syncer, interval, steps); err != nil {
+				return err
+			}
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("同期に失敗したプロジェクトがあります: %s", strings.Join(failed, ", "))
+		}
+		return nil
 	},
 }
 
+// syncProject はプロジェクトに同期処理を行います
+// stepsがnilの場合はすべての処理を行います。同期が最後まで完了したかを返します
+func syncProject(project Project, syncer *fileSyncer, hookTimeout time.Duration, steps syncSteps) bool {
+	fmt.Printf("プロジェクト %s を同期中...\n", project.Name)
+	syncer.beginProject(project)
+
+	// 途中で中断した場合も実行したフックの結果を表示する
+	var hookResults []hookResult
+	defer func() { printHookResults(hookResults) }()
+
+	// 同期前のフックを実行（失敗した場合は同期しない）
+	preResults, err := runSyncHooks(project, "pre_sync", project.PreSync, hookTimeout)
+	hookResults = append(hookResults, preResults...)
+	if err != nil {
+		fmt.Printf("%s の同期を中断しました: %v\n", project.Name, err)
+		return false
+	}
+
+	// .cursor ディレクトリをコピー
+	if steps.has(stepCursor) {
+		if err := syncCursor(project, syncer); err != nil {
+			fmt.Printf("%s の同期を中断しました: %v\n", project.Name, err)
+			return false
+		}
+	}
+
+	// repo setup相当の処理を実行
+	if err := setupRepo(project, syncer, steps); err != nil {
+		fmt.Printf("%s のrepo setup処理に失敗しました: %v\n", project.Name, err)
+		return false
+	}
+
+	// 同期後のフックを実行
+	postResults, err := runSyncHooks(project, "post_sync", project.PostSync, hookTimeout)
+	hookResults = append(hookResults, postResults...)
+	if err != nil {
+		fmt.Printf("%s の同期後の処理に失敗しました: %v\n", project.Name, err)
+		return false
+	}
+	fmt.Printf("%s の同期が完了しました（%s）\n", project.Name, syncer.stats)
+	return true
+}
+
+// syncCursor は~/.mei/cursorをプロジェクトの.cursorディレクトリにコピーします
+// ~/.mei/cursor が存在しない場合は組み込みのデフォルトを使用します
+func syncCursor(project Project, syncer *fileSyncer) error {
+	meiDir, err := meiHomeDir()
+	if err != nil {
+		return err
+	}
+	cursorSourceDir := filepath.Join(meiDir, "cursor")
+	targetDir := filepath.Join(project.Path, ".cursor")
+
+	if _, err = os.Stat(cursorSourceDir); os.IsNotExist(err) {
+		fmt.Println("~/.mei/cursor ディレクトリが存在しないため、組み込みのデフォルトを使用します（mei init で作成できます）")
+		err = syncer.copyUnitFS("cursor", defaultCursorFS(), targetDir)
+	} else {
+		err = syncer.copyUnit("cursor", cursorSourceDir, targetDir)
+	}
+	if err != nil {
+		return fmt.Errorf("cursorディレクトリのコピーに失敗しました: %w", err)
+	}
+	if conflicts := syncer.takeConflicts(); len(conflicts) > 0 {
+		return formatConflicts(conflicts)
+	}
+	return nil
+}
+
 // setupRepo はプロジェクトに対してrepo setup相当の処理を行います
-func setupRepo(project Project) error {
-	// gitディレクトリの確認
-	gitDir := filepath.Join(project.Path, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+// stepsがnilの場合はすべての処理を行います
+func setupRepo(project Project, syncer *fileSyncer, steps syncSteps) error {
+	// gitディレクトリの確認（worktreeやサブモジュールの.gitファイル、モノレポの親ディレクトリもたどる）
+	projects, err := syncer.registeredProjects()
+	if err != nil {
+		return err
+	}
+	repo, err := projects.repoFor(project.Path)
+	if errors.Is(err, gitrepo.ErrNotRepository) {
 		// Gitリポジトリがない場合はスキップ
 		fmt.Printf("%s はGitリポジトリではありません。スキップします。\n", project.Name)
 		return nil
 	}
+	if err != nil {
+		return fmt.Errorf("gitディレクトリの解決に失敗しました: %w", err)
+	}
+	if rel, err := repo.RelPath(project.Path); err == nil && rel != "." {
+		fmt.Printf("%s は %s のサブプロジェクト（%s）として同期します\n", project.Name, repo.WorkTree, rel)
+	}
 
-	// ホームディレクトリを取得
-	homeDir, err := os.UserHomeDir()
+	meiDir, err := meiHomeDir()
 	if err != nil {
-		return fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+		return err
+	}
+
+	if steps.has(stepExclude) {
+		if err := syncExclude(syncer, repo, meiDir); err != nil {
+			return err
+		}
+	}
+
+	if steps.has(stepHooks) {
+		if err := syncHooks(project, syncer, repo, meiDir); err != nil {
+			return err
+		}
+	}
+
+	if steps.has(stepGithub) {
+		if err := syncGithub(project, syncer, meiDir); err != nil {
+			return err
+		}
+	}
+
+	// GitユーザーとoriginはどちらもGitUserのidentityを使うため、一度だけ取得する
+	var identity config.Identity
+	if project.GitUser != "" && (steps.has(stepGitUser) || steps.has(stepRemote)) {
+		if identity, err = resolveIdentity(project.GitUser); err != nil {
+			return err
+		}
+	}
+
+	if steps.has(stepGitUser) {
+		if err := syncGitUser(project, syncer, repo, identity); err != nil {
+			return err
+		}
+	}
+
+	if steps.has(stepRemote) {
+		if err := syncRemote(project, syncer, repo, identity); err != nil {
+			return err
+		}
+	}
+
+	if steps.has(stepEnv) {
+		if err := syncEnv(project, syncer, meiDir); err != nil {
+			return err
+		}
 	}
-	meiDir := filepath.Join(homeDir, ".mei")
 
-	// .git/info/excludeファイルのパスを構築
-	excludePath := filepath.Join(gitDir, "info", "exclude")
+	return nil
+}
+
+// syncExclude は~/.mei/git/excludeの内容を.git/info/excludeに反映します
+func syncExclude(syncer *fileSyncer, repo *gitrepo.Repo, meiDir string) error {
+	// .git/info/excludeファイルのパスを構築（linked worktreeではメインのgitディレクトリのものを使う）
+	excludePath := repo.ExcludePath()
 
 	// info ディレクトリが存在しない場合は作成
 	infoDir := filepath.Dir(excludePath)
-	if err := os.MkdirAll(infoDir, 0755); err != nil {
+	if err := syncer.mkdirAll(infoDir); err != nil {
 		return fmt.Errorf("infoディレクトリの作成に失敗しました: %w", err)
 	}
 
 	// excludeファイルを更新
-	// ~/.mei/git/excludeからテンプレートを読み込む
+	// ~/.mei/git/excludeからテンプレートを読み込む（存在しない場合は組み込みのデフォルトを使用）
 	meiExcludePath := filepath.Join(meiDir, "git", "exclude")
 	excludeContent, err := os.ReadFile(meiExcludePath)
-	if err != nil {
+	if os.IsNotExist(err) {
+		excludeContent = defaultExclude()
+	} else if err != nil {
 		return fmt.Errorf("excludeテンプレートの読み込みに失敗しました: %w", err)
 	}
 	
-	blockManager := config.NewBlockManager("mei", string(excludeContent), "#")
-	if err := blockManager.UpdateFile(excludePath); err != nil {
-		return fmt.Errorf("excludeファイルの更新に失敗しました: %w", err)
+	// 同じリポジトリに登録されたサブプロジェクトで1つのブロックを共有する
+	projects, err := syncer.registeredProjects()
+	if err != nil {
+		return err
 	}
+	block := excludeBlockFor(string(excludeContent), repo, projects)
 
-	// ~/.mei/github ディレクトリからプロジェクト先の.githubディレクトリにファイルをコピー
-	meiGithubDir := filepath.Join(meiDir, "github")
-	if _, err := os.Stat(meiGithubDir); !os.IsNotExist(err) {
-		// ~/.mei/github ディレクトリが存在する場合
-		projectGithubDir := filepath.Join(project.Path, ".github")
-		if err := copyDir(meiGithubDir, projectGithubDir); err != nil {
-			fmt.Printf("警告: %s の.githubディレクトリのコピーに失敗しました: %v\n", project.Name, err)
-		} else {
-			fmt.Printf("%s の.githubディレクトリを更新しました\n", project.Name)
-		}
-	}
-
-	// GitUser設定が指定されている場合はGit設定を更新
-	if project.GitUser != "" {
-		// リポジトリのルートディレクトリパスを取得（.gitの親ディレクトリ）
-		repoRoot := filepath.Dir(gitDir)
-		repoName := filepath.Base(repoRoot)
-
-		// Git設定を実行
-		gitCommands := []struct {
-			args []string
-			desc string
-			ignoreError bool
-		}{
-			{[]string{"config", "--local", "user.name", project.GitUser}, "ユーザー名の設定", false},
-			{[]string{"config", "--local", "user.email", project.GitUser + "@gmail.com"}, "メールアドレスの設定", false},
-			// originの削除（存在しない場合のエラーは無視）
-			{[]string{"remote", "remove", "origin"}, "既存のoriginの削除", true},
-			{[]string{"remote", "add", "origin", fmt.Sprintf("git@%s.github.com:%s/%s.git", project.GitUser, project.GitUser, repoName)}, "リモートの設定", false},
-		}
-
-		for _, cmd := range gitCommands {
-			if err := runGitCommand(gitDir, cmd.args...); err != nil && !cmd.ignoreError {
-				return fmt.Errorf("%sに失敗しました: %w", cmd.desc, err)
-			}
-		}
-		fmt.Printf("%s のGitユーザー設定を更新しました（%s）\n", project.Name, project.GitUser)
+	if err := syncer.track(excludePath); err != nil {
+		return err
 	}
+	blockManager := config.NewBlockManager("mei", block, "#")
+	if err := syncer.updateBlock(blockManager, excludePath); err != nil {
+		return fmt.Errorf("excludeファイルの更新に失敗しました: %w", err)
+	}
+	return nil
+}
 
-	// EnvKeys設定が指定されている場合は環境変数を更新
-	if len(project.EnvKeys) > 0 {
-		// 環境変数ファイルのパスを~/.mei/env/に変更
-		envFileDest := filepath.Join(project.Path, ".env")
-		
-		for _, key := range project.EnvKeys {
-			envFileSource := filepath.Join(meiDir, "env", key)
-			
-			// .envファイルが存在しない場合はスキップ
-			if _, err := os.Stat(envFileSource); os.IsNotExist(err) {
-				fmt.Printf("警告: envファイルが見つかりません: %s\n", envFileSource)
-				continue
-			}
-			
-			// .envファイルの内容を読み込む
-			content, err := os.ReadFile(envFileSource)
-			if err != nil {
-				fmt.Printf("警告: .envファイルの読み込みに失敗しました: %v\n", err)
-				continue
+// excludeBlockFor はexcludeファイルを共有する登録済みプロジェクトすべてに対応するブロックの内容を返します
+// ルートからのパスを指定するパターン（スラッシュを含むもの）はサブプロジェクトごとにそのパスを前に付けて展開します
+func excludeBlockFor(template string, repo *gitrepo.Repo, projects *projectIndex) string {
+	siblings := projects.siblings(repo)
+
+	// excludeファイルを共有するプロジェクトの作業ツリーのルートからの相対パスを集める
+	relSet := map[string]bool{}
+	for _, sibling := range siblings {
+		relSet[filepath.ToSlash(sibling.rel)] = true
+	}
+	if len(relSet) == 0 {
+		relSet["."] = true
+	}
+	rels := make([]string, 0, len(relSet))
+	for rel := range relSet {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(template, "\n"), "\n") {
+		pattern := strings.TrimSpace(line)
+		negate := strings.HasPrefix(pattern, "!")
+		body := strings.TrimPrefix(pattern, "!")
+		if pattern == "" || strings.HasPrefix(pattern, "#") || !strings.Contains(strings.TrimSuffix(body, "/"), "/") {
+			// どの階層にも一致するパターンはそのまま
+			lines = append(lines, line)
+			continue
+		}
+		for _, rel := range rels {
+			expanded := "/" + strings.TrimPrefix(body, "/")
+			if rel != "." {
+				expanded = "/" + rel + expanded
 			}
-			
-			// BlockManagerを使って.envファイルに追記・上書き
-			blockManager := config.NewBlockManager(key, string(content), "#")
-			if err := blockManager.UpdateFile(envFileDest); err != nil {
-				fmt.Printf("警告: .envファイルの更新に失敗しました: %v\n", err)
-				continue
+			if negate {
+				expanded = "!" + expanded
 			}
-			
-			fmt.Printf("%s の環境変数(%s)を更新しました\n", project.Name, key)
+			lines = append(lines, expanded)
 		}
 	}
+	return strings.Join(lines, "\n") + "\n"
+}
 
-	return nil
+// repoProject はリポジトリに登録されたプロジェクトと作業ツリーのルートからの相対パスです
+type repoProject struct {
+	Project
+	rel string
 }
 
-// ディレクトリをコピーする関数
-func copyDir(src, dst string) error {
-	// 対象ディレクトリを作成（既に存在する場合は何もしない）
-	err := os.MkdirAll(dst, 0755)
+// repoProjects はrepoとgitディレクトリを共有する登録済みプロジェクトを返します
+// 同期中はfileSyncerが読み込んだprojectIndexを使い、プロジェクトごとに読み込み直さないようにします
+func repoProjects(repo *gitrepo.Repo) ([]repoProject, error) {
+	projects, err := loadProjects()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return newProjectIndex(projects).siblings(repo), nil
+}
 
-	// ソースディレクトリ内のファイルとディレクトリを取得
-	entries, err := os.ReadDir(src)
-	if err != nil {
-		return err
-	}
+// projectIndex は登録済みプロジェクトと、それぞれを含むGitリポジトリの対応です
+// 同期中に何度も参照するため、リポジトリの探索はパスごとに一度だけ行います
+type projectIndex struct {
+	projects []Project
+	// discover はパスを含むGitリポジトリを探します
+	discover func(path string) (*gitrepo.Repo, error)
+	repos    map[string]discoveredRepo
+}
 
-	for _, entry := range entries {
-		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
+// discoveredRepo はリポジトリの探索結果です
+type discoveredRepo struct {
+	repo *gitrepo.Repo
+	err  error
+}
 
-		if entry.IsDir() {
-			// サブディレクトリの場合は再帰的にコピー
-			err = copyDir(srcPath, dstPath)
-			if err != nil {
-				return err
-			}
-		} else {
-			// ファイルの場合はコピー
-			err = copyFile(srcPath, dstPath)
-			if err != nil {
-				return err
-			}
-		}
+func newProjectIndex(projects []Project) *projectIndex {
+	return &projectIndex{
+		projects: projects,
+		discover: discoverRepo,
+		repos:    make(map[string]discoveredRepo),
 	}
+}
 
-	return nil
+// repoFor はpathを含むGitリポジトリを返します
+func (x *projectIndex) repoFor(path string) (*gitrepo.Repo, error) {
+	if found, ok := x.repos[path]; ok {
+		return found.repo, found.err
+	}
+	repo, err := x.discover(path)
+	x.repos[path] = discoveredRepo{repo: repo, err: err}
+	return repo, err
 }
 
-// ファイルをコピーする関数
-func copyFile(src, dst string) error {
-	// ソースファイルを開く
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return err
+// siblings はrepoとgitディレクトリを共有する登録済みプロジェクトを返します
+func (x *projectIndex) siblings(repo *gitrepo.Repo) []repoProject {
+	var siblings []repoProject
+	for _, project := range x.projects {
+		projectRepo, err := x.repoFor(project.Path)
+		if err != nil || projectRepo.CommonDir != repo.CommonDir {
+			continue
+		}
+		rel, err := projectRepo.RelPath(project.Path)
+		if err != nil {
+			continue
+		}
+		siblings = append(siblings, repoProject{Project: project, rel: rel})
 	}
-	defer srcFile.Close()
+	return siblings
+}
 
-	// 宛先ファイルを作成
-	dstFile, err := os.Create(dst)
+// discoverRepo はプロジェクトを含むGitリポジトリを探します
+// ホームディレクトリ自体のリポジトリはプロジェクトがホームディレクトリでない限り対象にしません
+func discoverRepo(path string) (*gitrepo.Repo, error) {
+	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
 	}
-	defer dstFile.Close()
+	return gitrepo.Discover(path, homeDir)
+}
 
-	// ファイルの内容をコピー
-	_, err = io.Copy(dstFile, srcFile)
-	if err != nil {
+// syncGithub は~/.mei/github ディレクトリからプロジェクト先の.githubディレクトリにファイルをコピーします
+func syncGithub(project Project, syncer *fileSyncer, meiDir string) error {
+	// mei initで作成しただけの空のディレクトリでは何もしない
+	meiGithubDir := filepath.Join(meiDir, "github")
+	if empty, err := isEmptyTree(meiGithubDir); err != nil || empty {
 		return err
 	}
 
-	// ファイルの権限をコピー
-	srcInfo, err := os.Stat(src)
-	if err != nil {
-		return err
+	// ~/.mei/github ディレクトリにファイルがある場合
+	projectGithubDir := filepath.Join(project.Path, ".github")
+	before := syncer.stats
+	if err := syncer.copyUnit("github", meiGithubDir, projectGithubDir); err != nil {
+		fmt.Printf("警告: %s の.githubディレクトリのコピーに失敗しました: %v\n", project.Name, err)
+	} else if conflicts := syncer.takeConflicts(); len(conflicts) > 0 {
+		return formatConflicts(conflicts)
+	} else if syncer.stats.Created != before.Created || syncer.stats.Updated != before.Updated {
+		fmt.Printf("%s の.githubディレクトリを更新しました\n", project.Name)
 	}
-	return os.Chmod(dst, srcInfo.Mode())
+	return nil
 }
 
-// copyCursorDirectory は.cursorディレクトリをコピーします
-func copyCursorDirectory(destRoot string) error {
-	return fs.WalkDir(cursorFS, "templates/.cursor", func(path string, d fs.DirEntry, err error) error {
+// isEmptyTree はdirが存在しないか、ディレクトリ以外のものを含まない場合にtrueを返します
+func isEmptyTree(dir string) (bool, error) {
+	empty := true
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		if !d.IsDir() {
+			empty = false
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	return empty, err
+}
 
-		// 相対パスを計算
-		relPath, err := filepath.Rel("templates/.cursor", path)
-		if err != nil {
+// syncEnv はEnvKeys設定が指定されている場合に環境変数を更新します
+func syncEnv(project Project, syncer *fileSyncer, meiDir string) error {
+	// 環境変数ファイルのパスを~/.mei/env/に変更
+	envFileDest := filepath.Join(project.Path, ".env")
+	if len(project.EnvKeys) > 0 {
+		if err := syncer.track(envFileDest); err != nil {
 			return err
 		}
-		destPath := filepath.Join(destRoot, ".cursor", relPath)
-
-		if d.IsDir() {
-			return os.MkdirAll(destPath, 0755)
+	}
+	
+	for _, key := range project.EnvKeys {
+		envFileSource := filepath.Join(meiDir, "env", key)
+		
+		// .envファイルが存在しない場合はスキップ
+		if _, err := os.Stat(envFileSource); os.IsNotExist(err) {
+			fmt.Printf("警告: envファイルが見つかりません: %s\n", envFileSource)
+			continue
 		}
-
-		// ファイルの場合
-		data, err := cursorFS.ReadFile(path)
+		
+		// .envファイルの内容を読み込む
+		content, err := os.ReadFile(envFileSource)
 		if err != nil {
-			return err
+			fmt.Printf("警告: .envファイルの読み込みに失敗しました: %v\n", err)
+			continue
 		}
-
-		return os.WriteFile(destPath, data, 0644)
-	})
+		
+		// BlockManagerを使って.envファイルに追記・上書き
+		blockManager := config.NewBlockManager(key, string(content), "#")
+		current, err := os.ReadFile(envFileDest)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Printf("警告: .envファイルの読み込みに失敗しました: %v\n", err)
+			continue
+		}
+		// 内容が変わらない場合は更新したと表示しない
+		if blockManager.Apply(string(current)) == string(current) {
+			continue
+		}
+		if err := syncer.updateBlock(blockManager, envFileDest); err != nil {
+			fmt.Printf("警告: .envファイルの更新に失敗しました: %v\n", err)
+			continue
+		}
+		if syncer.dryRun {
+			continue
+		}
+		
+		fmt.Printf("%s の環境変数(%s)を更新しました\n", project.Name, key)
+	}
+	return nil
 }
 
-// runGitCommand はGitコマンドを実行します
-func runGitCommand(gitDir string, args ...string) error {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = filepath.Dir(gitDir) // .gitの親ディレクトリで実行
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+// meiHomeDir は~/.meiディレクトリのパスを返します
+func meiHomeDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+	}
+	return filepath.Join(homeDir, ".mei"), nil
 }
 
 func init() {
 	projectCmd.AddCommand(projectSyncCmd)
-} 
\ No newline at end of file
+	projectSyncCmd.Flags().Duration("hook-timeout", defaultHookTimeout, "pre_sync/post_syncフックのタイムアウトを指定します")
+	projectSyncCmd.Flags().String("on-conflict", "", "ローカルで変更されたファイルの扱いを指定します (skip|overwrite|backup|merge)")
+	projectSyncCmd.Flags().String("symlinks", string(symlinkPreserve), "同期元にあるシンボリックリンクの扱いを指定します (preserve|follow|skip)")
+	projectSyncCmd.Flags().Bool("no-remote", false, "originのホストをidentityのSSHホストエイリアスに書き換えません")
+	projectSyncCmd.Flags().Bool("watch", false, "同期後も~/.meiの同期元を監視し、変更があれば再同期します")
+	projectSyncCmd.Flags().Duration("watch-interval", defaultWatchInterval, "--watch時に同期元の変更を確認する間隔を指定します")
+}
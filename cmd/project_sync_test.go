@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mei/internal/config"
+)
+
+// captureStdout はfnが標準出力に書き込んだ内容を返します
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	defer func() {
+		os.Stdout = stdout
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestSyncGithub(t *testing.T) {
+	const updated = "の.githubディレクトリを更新しました"
+
+	tests := []struct {
+		name        string
+		files       map[string]string // ~/.mei/github配下のファイル
+		dirs        []string          // ~/.mei/github配下の空のディレクトリ
+		wantGithub  bool
+		wantUpdated []bool // 実行ごとに更新を表示するか
+	}{
+		{name: "no source dir", wantUpdated: []bool{false}},
+		{name: "empty source dir", dirs: []string{"."}, wantUpdated: []bool{false, false}},
+		{name: "only empty subdirs", dirs: []string{"workflows"}, wantUpdated: []bool{false}},
+		{
+			name:        "only excluded files",
+			files:       map[string]string{".meiignore": "*\n", "workflows/ci.yml": "on: push\n"},
+			wantUpdated: []bool{false},
+		},
+		{
+			name:        "copies files once",
+			files:       map[string]string{"workflows/ci.yml": "on: push\n"},
+			wantGithub:  true,
+			wantUpdated: []bool{true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer := newCopySyncer(t)
+			meiDir := filepath.Join(os.Getenv("HOME"), ".mei")
+			for _, dir := range tt.dirs {
+				if err := os.MkdirAll(filepath.Join(meiDir, "github", dir), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for name, content := range tt.files {
+				writeTestFile(t, filepath.Join(meiDir, "github", name), content)
+			}
+			project := Project{Name: "app", Path: t.TempDir()}
+
+			for i, wantUpdated := range tt.wantUpdated {
+				var err error
+				output := captureStdout(t, func() {
+					err = syncGithub(project, syncer, meiDir)
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := strings.Contains(output, updated); got != wantUpdated {
+					t.Errorf("%d回目の出力 %q: 更新の表示 = %v; want %v", i+1, output, got, wantUpdated)
+				}
+			}
+
+			_, err := os.Stat(filepath.Join(project.Path, ".github"))
+			if got := err == nil; got != tt.wantGithub {
+				t.Errorf(".githubディレクトリの有無 = %v; want %v", got, tt.wantGithub)
+			}
+		})
+	}
+}
+
+func TestSyncProjectPrintsPreSyncResultsOnFailure(t *testing.T) {
+	syncer := newCopySyncer(t)
+	project := Project{Name: "app", Path: t.TempDir(), PreSync: []string{"echo pre-sync-ran"}}
+	// .cursorがファイルのためcursorの同期が失敗する
+	writeTestFile(t, filepath.Join(os.Getenv("HOME"), ".mei", "cursor", "rules", "a.mdc"), "a")
+	writeTestFile(t, filepath.Join(project.Path, ".cursor"), "not a dir")
+
+	var ok bool
+	output := captureStdout(t, func() {
+		ok = syncProject(project, syncer, defaultHookTimeout, nil)
+	})
+	if ok {
+		t.Fatalf("syncProject() = true, want false; output:\n%s", output)
+	}
+	if !strings.Contains(output, "[pre_sync] echo pre-sync-ran") || !strings.Contains(output, "    pre-sync-ran") {
+		t.Errorf("pre_syncの結果が表示されていません:\n%s", output)
+	}
+}
+
+func TestSyncEnv(t *testing.T) {
+	apiBlock := config.NewBlockManager("api", "API_KEY=secret\n", "#").Format()
+	dbBlock := config.NewBlockManager("db", "DB_URL=postgres://localhost\n", "#").Format()
+	tests := []struct {
+		name       string
+		envKeys    []string
+		existing   *string // 同期前の.env（nilの場合は存在しない）
+		dryRun     bool
+		want       *string // 同期後の.env（nilの場合は作成されない）
+		wantOutput []string
+	}{
+		{
+			name:    "no env keys",
+			envKeys: nil,
+		},
+		{
+			name:       "creates .env",
+			envKeys:    []string{"api"},
+			want:       ptr(apiBlock),
+			wantOutput: []string{"app の環境変数(api)を更新しました"},
+		},
+		{
+			name:       "keeps user variables",
+			envKeys:    []string{"api", "db"},
+			existing:   ptr("DEBUG=1\n"),
+			want:       ptr("DEBUG=1\n\n" + apiBlock + "\n" + dbBlock),
+			wantOutput: []string{"app の環境変数(api)を更新しました", "app の環境変数(db)を更新しました"},
+		},
+		{
+			name:       "replaces an outdated block",
+			envKeys:    []string{"api"},
+			existing:   ptr("DEBUG=1\n\n# BEGIN:api\nAPI_KEY=old\n# END:api\n"),
+			want:       ptr("DEBUG=1\n\n" + apiBlock),
+			wantOutput: []string{"app の環境変数(api)を更新しました"},
+		},
+		{
+			name:     "unchanged block is not reported",
+			envKeys:  []string{"api"},
+			existing: ptr(apiBlock),
+			want:     ptr(apiBlock),
+		},
+		{
+			name:       "missing source is skipped",
+			envKeys:    []string{"missing", "api"},
+			want:       ptr(apiBlock),
+			wantOutput: []string{"警告: envファイルが見つかりません", "app の環境変数(api)を更新しました"},
+		},
+		{
+			name:       "dry run",
+			envKeys:    []string{"api"},
+			existing:   ptr("DEBUG=1\n"),
+			dryRun:     true,
+			want:       ptr("DEBUG=1\n"),
+			wantOutput: []string{"のブロック api を更新します"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer := newCopySyncer(t)
+			syncer.dryRun = tt.dryRun
+			meiDir := filepath.Join(os.Getenv("HOME"), ".mei")
+			writeTestFile(t, filepath.Join(meiDir, "env", "api"), "API_KEY=secret\n")
+			writeTestFile(t, filepath.Join(meiDir, "env", "db"), "DB_URL=postgres://localhost\n")
+			project := Project{Name: "app", Path: t.TempDir(), EnvKeys: tt.envKeys}
+			envPath := filepath.Join(project.Path, ".env")
+			if tt.existing != nil {
+				writeTestFile(t, envPath, *tt.existing)
+			}
+
+			output := captureStdout(t, func() {
+				if err := syncEnv(project, syncer, meiDir); err != nil {
+					t.Fatalf("syncEnv() error = %v", err)
+				}
+			})
+
+			content, err := os.ReadFile(envPath)
+			switch {
+			case tt.want == nil:
+				if !os.IsNotExist(err) {
+					t.Errorf(".envが作成されています: %q, %v", content, err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case string(content) != *tt.want:
+				t.Errorf(".env = %q, want %q", content, *tt.want)
+			}
+			if len(tt.wantOutput) == 0 && output != "" {
+				t.Errorf("出力 = %q, want empty", output)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(output, want) {
+					t.Errorf("出力に %q がありません:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
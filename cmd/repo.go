@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// repoCmd はリポジトリ関連のコマンドを表します
+var repoCmd = &cobra.Command{
+	Use:     "repo",
+	Aliases: []string{"r"},
+	Short:   "リポジトリ関連のコマンドです",
+}
+
+func init() {
+	rootCmd.AddCommand(repoCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"mei/internal/config"
+	"mei/internal/gitrepo"
+	"github.com/spf13/cobra"
+)
+
+var repoAddCmd = &cobra.Command{
+	Use:   "add [path]",
+	Short: "リポジトリをお気に入りに登録します（省略時は現在のディレクトリ）",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := argOrCurrentDir(args)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// サブディレクトリで実行した場合もリポジトリのルートを登録する
+		repo, err := discoverRepo(path)
+		if errors.Is(err, gitrepo.ErrNotRepository) {
+			fmt.Printf("%s はGitリポジトリではありません\n", path)
+			return
+		}
+		if err != nil {
+			fmt.Println("gitディレクトリの解決に失敗しました:", err)
+			return
+		}
+
+		favorites, err := config.LoadFavorites()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := favorites.Add(repo.WorkTree); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := favorites.Save(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("リポジトリを登録しました: %s\n", repo.WorkTree)
+	},
+}
+
+// argOrCurrentDir は引数で指定されたパス、または現在のディレクトリを絶対パスで返します
+func argOrCurrentDir(args []string) (string, error) {
+	if len(args) > 0 {
+		return filepath.Abs(expandHome(args[0]))
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("現在のディレクトリを取得できませんでした: %w", err)
+	}
+	return dir, nil
+}
+
+func init() {
+	repoCmd.AddCommand(repoAddCmd)
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mei/internal/config"
+	"mei/internal/gitrepo"
+	"github.com/spf13/cobra"
+)
+
+var repoCloneCmd = &cobra.Command{
+	Use:   "clone <owner/repo|url>",
+	Short: "identityのSSHホストエイリアスでリポジトリをクローンし、プロジェクトとして登録して同期します",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitUser, _ := cmd.Flags().GetString("user")
+		if gitUser == "" {
+			fmt.Println("--user でGitユーザー名（identity）を指定してください")
+			return
+		}
+		identity, err := resolveIdentity(gitUser)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		remoteURL, repoName, err := cloneURL(args[0], identity)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// クローン先を決定（省略時は現在のディレクトリ）
+		into, _ := cmd.Flags().GetString("into")
+		if into == "" {
+			if into, err = os.Getwd(); err != nil {
+				fmt.Println("現在のディレクトリを取得できませんでした:", err)
+				return
+			}
+		}
+		into, err = filepath.Abs(expandHome(into))
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		dest := filepath.Join(into, repoName)
+		if _, err := os.Stat(dest); err == nil {
+			fmt.Printf("%s は既に存在します\n", dest)
+			return
+		}
+		if err := os.MkdirAll(into, 0755); err != nil {
+			fmt.Println("クローン先のディレクトリを作成できませんでした:", err)
+			return
+		}
+
+		// クローンにSSHホストエイリアスを使うため、先に~/.ssh/configを更新する
+		if err := updateSSHConfig(nil); err != nil {
+			fmt.Printf("警告: %v\n", err)
+		}
+
+		// クローンはリポジトリの大きさによって時間がかかるためタイムアウトを設けない
+		// 相対パスのクローン元を指定どおりに解決するため、現在のディレクトリで実行する（クローン先は絶対パス）
+		fmt.Printf("%s をクローンしています...\n", remoteURL)
+		if _, err := gitRunner.Run(context.Background(), "", "clone", "--quiet", remoteURL, dest); err != nil {
+			fmt.Println("クローンに失敗しました:", err)
+			return
+		}
+
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		project := Project{
+			Name:      repoName,
+			Path:      dest,
+			GitUser:   gitUser,
+			Tags:      tags,
+			CreatedAt: time.Now(),
+		}
+		if err := registerProject(project); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("プロジェクトを登録しました: %s\n", dest)
+
+		// 登録したプロジェクトをすぐに同期する
+		state, err := config.LoadSyncState()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		manifest, err := config.LoadSyncManifest()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		syncer := newFileSyncer(state, manifest, conflictFail, symlinkPreserve)
+		syncProject(project, syncer, defaultHookTimeout, nil)
+		if err := state.Save(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := syncer.saveSnapshot(); err != nil {
+			fmt.Println(err)
+		}
+	},
+}
+
+// cloneURL はクローンに使うURLとリポジトリ名を返します
+// owner/repoの形式はidentityのSSHホストエイリアスのURLにし、URLが指定された場合はidentityと同じホストならホストだけを書き換えます
+// ローカルのパスやfile://のURLはそのまま使います
+func cloneURL(arg string, identity config.Identity) (string, string, error) {
+	if isLocalRemote(arg) {
+		location := strings.TrimPrefix(arg, "file://")
+		repo := strings.TrimSuffix(filepath.Base(filepath.Clean(location)), ".git")
+		if repo == "" || repo == "." || repo == string(filepath.Separator) {
+			return "", "", fmt.Errorf("パスからリポジトリ名を取得できません: %s", arg)
+		}
+		return arg, repo, nil
+	}
+
+	if owner, repo, ok := strings.Cut(arg, "/"); ok && !strings.Contains(arg, ":") && !strings.Contains(repo, "/") {
+		repo = strings.TrimSuffix(repo, ".git")
+		if owner == "" || repo == "" {
+			return "", "", fmt.Errorf("owner/repo の形式で指定してください: %s", arg)
+		}
+		return identity.RemoteURL(owner, repo), repo, nil
+	}
+
+	remote, err := gitrepo.ParseRemote(arg)
+	if err != nil {
+		return "", "", err
+	}
+	repo := strings.TrimSuffix(path.Base(remote.Path), ".git")
+	if repo == "" || repo == "." || repo == "/" {
+		return "", "", fmt.Errorf("リモートURLからリポジトリ名を取得できません: %s", arg)
+	}
+	if !sameRemoteHost(remote.Host, identity) {
+		fmt.Printf("警告: %s はidentityのホスト %s と異なるため、URLをそのまま使用します\n", remote.Host, identity.Host)
+		return arg, repo, nil
+	}
+	return remote.SSHURL(identity.SSHHost), repo, nil
+}
+
+// isLocalRemote はクローン元がローカルのリポジトリかを返します
+// 絶対パスと.、..、./、../で始まるパス、file://のURLをローカルとみなします
+// owner/repoの形式は同じ名前のディレクトリがあってもローカルとはみなしません（実行するディレクトリで結果が変わらないようにするため）
+func isLocalRemote(arg string) bool {
+	if strings.HasPrefix(arg, "file://") || filepath.IsAbs(arg) {
+		return true
+	}
+	return arg == "." || arg == ".." || strings.HasPrefix(arg, "./") || strings.HasPrefix(arg, "../")
+}
+
+func init() {
+	repoCmd.AddCommand(repoCloneCmd)
+	repoCloneCmd.Flags().String("user", "", "Gitユーザー名（~/.mei/identities.ymlのidentity）を指定します")
+	repoCloneCmd.Flags().String("into", "", "クローン先の親ディレクトリを指定します（省略時は現在のディレクトリ）")
+	repoCloneCmd.Flags().StringSlice("tag", nil, "プロジェクトのタグを指定します（複数指定可）")
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mei/internal/config"
+)
+
+func TestCloneURL(t *testing.T) {
+	identity := config.Identity{Host: "github.com", SSHHost: "github.com-work", Owner: "acme"}
+	local := t.TempDir()
+	if err := os.Mkdir(filepath.Join(local, "repos"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(local, "repos", "lib.git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(local)
+
+	tests := []struct {
+		arg      string
+		wantURL  string
+		wantName string
+	}{
+		{"acme/app", "git@github.com-work:acme/app.git", "app"},
+		{"acme/app.git", "git@github.com-work:acme/app.git", "app"},
+		{"git@github.com:acme/app.git", "git@github.com-work:acme/app.git", "app"},
+		{"https://github.com/acme/app.git", "git@github.com-work:acme/app.git", "app"},
+		{"ssh://git@github.com:2222/acme/app.git", "ssh://git@github.com-work:2222/acme/app.git", "app"},
+		{"https://gitlab.com/acme/app.git", "https://gitlab.com/acme/app.git", "app"},
+		{"/srv/git/app.git", "/srv/git/app.git", "app"},
+		{"/srv/git/app/", "/srv/git/app/", "app"},
+		{"./app.git", "./app.git", "app"},
+		{"../app", "../app", "app"},
+		{"file:///srv/git/app.git", "file:///srv/git/app.git", "app"},
+		{"./repos/lib.git", "./repos/lib.git", "lib"},
+		// 同じ名前のディレクトリがあってもowner/repoの形式として扱う
+		{"repos/lib.git", "git@github.com-work:repos/lib.git", "lib"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			gotURL, gotName, err := cloneURL(tt.arg, identity)
+			if err != nil {
+				t.Fatalf("cloneURL(%q): %v", tt.arg, err)
+			}
+			if gotURL != tt.wantURL || gotName != tt.wantName {
+				t.Errorf("cloneURL(%q) = %q, %q; want %q, %q", tt.arg, gotURL, gotName, tt.wantURL, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestRepoCloneLocal(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("gitがインストールされていません")
+	}
+
+	for _, scheme := range []string{"", "file://"} {
+		t.Run("scheme="+scheme, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			writeTestFile(t, filepath.Join(home, ".mei", "identities.yml"), testIdentities)
+
+			bare := filepath.Join(t.TempDir(), "app.git")
+			if output, err := exec.Command("git", "init", "--quiet", "--bare", bare).CombinedOutput(); err != nil {
+				t.Fatalf("git init --bare: %v: %s", err, output)
+			}
+			source := scheme + bare
+
+			into := filepath.Join(home, "src")
+			rootCmd.SetArgs([]string{"repo", "clone", "--user", "work", "--into", into, source})
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			dest := filepath.Join(into, "app")
+			projects, err := loadProjects()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(projects) != 1 {
+				t.Fatalf("登録されたプロジェクト: %+v", projects)
+			}
+			if got := projects[0]; got.Name != "app" || got.Path != dest || got.GitUser != "work" {
+				t.Errorf("登録されたプロジェクト = %+v; want app %s work", got, dest)
+			}
+
+			// ローカルのクローン元はidentityのホストエイリアスに書き換えない
+			origin, err := exec.Command("git", "-C", dest, "remote", "get-url", "origin").Output()
+			if err != nil {
+				t.Fatalf("git remote get-url origin: %v", err)
+			}
+			if got := strings.TrimSpace(string(origin)); got != source {
+				t.Errorf("origin = %q; want %q", got, source)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"mei/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var repoLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "お気に入りのリポジトリのパスを1行に1つずつ表示します",
+	Run: func(cmd *cobra.Command, args []string) {
+		favorites, err := config.LoadFavorites()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+
+		// jjrなどでパイプに渡せるように、標準出力にはパスだけを出力する
+		repos := favorites.GetValidRepositories()
+		if len(repos) == 0 {
+			fmt.Fprintln(os.Stderr, "登録されているリポジトリはありません（mei repo add で登録できます）")
+			return
+		}
+		for _, repo := range repos {
+			fmt.Println(repo)
+		}
+	},
+}
+
+func init() {
+	repoCmd.AddCommand(repoLsCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"mei/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var repoRmCmd = &cobra.Command{
+	Use:   "rm [path]",
+	Short: "リポジトリをお気に入りから削除します（省略時は現在のディレクトリ）",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := argOrCurrentDir(args)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		favorites, err := config.LoadFavorites()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// 登録されたパスでなければ、サブディレクトリとみなしてリポジトリのルートを削除する
+		// 既に削除されたリポジトリも指定できるように、まず登録されたパスと比較する
+		if !slices.Contains(favorites.Repositories, path) {
+			if repo, err := discoverRepo(path); err == nil {
+				path = repo.WorkTree
+			}
+		}
+
+		if err := favorites.Remove(path); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := favorites.Save(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("リポジトリを削除しました: %s\n", path)
+	},
+}
+
+var repoPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "存在しなくなったリポジトリをお気に入りから削除します",
+	Run: func(cmd *cobra.Command, args []string) {
+		favorites, err := config.LoadFavorites()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		removed := favorites.Prune()
+		if len(removed) == 0 {
+			fmt.Println("削除するリポジトリはありません")
+			return
+		}
+		if err := favorites.Save(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, path := range removed {
+			fmt.Printf("リポジトリを削除しました: %s\n", path)
+		}
+	},
+}
+
+func init() {
+	repoCmd.AddCommand(repoRmCmd)
+	repoCmd.AddCommand(repoPruneCmd)
+}
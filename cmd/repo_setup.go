@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"mei/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// repoSetupSteps はmei repo setupで選択できる同期処理です
+var repoSetupSteps = []syncStep{stepExclude, stepHooks, stepGithub, stepGitUser, stepRemote, stepEnv}
+
+var repoSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "現在のリポジトリにexclude、.github、Gitユーザー、envの設定を行います（プロジェクトには登録しません）",
+	Run: func(cmd *cobra.Command, args []string) {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			fmt.Println("現在のディレクトリを取得できませんでした:", err)
+			return
+		}
+
+		// 実行する処理を取得
+		stepNames, _ := cmd.Flags().GetStringSlice("steps")
+		steps, err := parseRepoSetupSteps(stepNames)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// 競合時の振る舞いを取得
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		policy, err := parseConflictPolicy(onConflict)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// 登録せずに、フラグの内容で一時的なプロジェクトを作成
+		gitUser, _ := cmd.Flags().GetString("user")
+		envKeys, _ := cmd.Flags().GetStringSlice("env")
+		project := Project{
+			Name:    filepath.Base(currentDir),
+			Path:    currentDir,
+			GitUser: gitUser,
+			EnvKeys: envKeys,
+		}
+
+		state, err := config.LoadSyncState()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		manifest, err := config.LoadSyncManifest()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		syncer := newFileSyncer(state, manifest, policy, symlinkPreserve)
+		syncer.dryRun, _ = cmd.Flags().GetBool("dry-run")
+		syncer.beginProject(project)
+
+		if syncer.dryRun {
+			fmt.Println("dry-run: 変更は行わず、実行する内容だけを表示します")
+		}
+
+		if err := setupRepo(project, syncer, steps); err != nil {
+			fmt.Printf("%s のrepo setup処理に失敗しました: %v\n", project.Name, err)
+			return
+		}
+
+		if syncer.dryRun {
+			fmt.Printf("%s に変更は行っていません（作成予定: %d、更新予定: %d、変更なし: %d）\n",
+				project.Name, syncer.stats.Created, syncer.stats.Updated, syncer.stats.Unchanged)
+			return
+		}
+
+		// 書き込んだ内容を次回の競合検出のために保存し、変更前の状態を元に戻せるようにする
+		if err := state.Save(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := syncer.saveSnapshot(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("%s のrepo setupが完了しました（%s）\n", project.Name, syncer.stats)
+	},
+}
+
+// parseRepoSetupSteps は--stepsフラグの値を検証します（指定がない場合はすべての処理を表すnilを返します）
+func parseRepoSetupSteps(names []string) (syncSteps, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	steps := syncSteps{}
+	for _, name := range names {
+		step := syncStep(name)
+		if !slices.Contains(repoSetupSteps, step) {
+			return nil, fmt.Errorf("不明な--stepsの値です: %s (exclude, hooks, github, git-user, remote, env のいずれか)", name)
+		}
+		steps[step] = true
+	}
+	return steps, nil
+}
+
+func init() {
+	repoCmd.AddCommand(repoSetupCmd)
+	repoSetupCmd.Flags().String("user", "", "Gitユーザー名（~/.mei/identities.ymlのidentity）を指定します")
+	repoSetupCmd.Flags().StringSlice("env", nil, ".envに書き込む環境変数キーを指定します（複数指定可）")
+	repoSetupCmd.Flags().StringSlice("steps", nil, "実行する処理を指定します（exclude, hooks, github, git-user, remote, env。省略時はすべて）")
+	repoSetupCmd.Flags().Bool("dry-run", false, "変更を行わずに実行する内容を表示します")
+	repoSetupCmd.Flags().String("on-conflict", "", "ローカルで変更されたファイルの扱いを指定します (skip|overwrite|backup|merge)")
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// repoStatus はプロジェクトのGitの状態です
+type repoStatus struct {
+	Project    string     `json:"project"`
+	Path       string     `json:"path"`
+	Branch     string     `json:"branch,omitempty"`
+	Upstream   string     `json:"upstream,omitempty"`
+	Dirty      int        `json:"dirty"`     // 変更されたファイル数（ステージ済みを含む）
+	Untracked  int        `json:"untracked"` // 追跡されていないファイル数
+	Ahead      int        `json:"ahead"`     // upstreamより進んでいるコミット数
+	Behind     int        `json:"behind"`    // upstreamより遅れているコミット数
+	Stashes    int        `json:"stashes"`
+	LastCommit *time.Time `json:"last_commit,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+var repoStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "登録されているプロジェクトのブランチや未コミットの変更などを一覧表示します",
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "table" && format != "json" {
+			fmt.Printf("不明な--formatの値です: %s (table, json のいずれか)\n", format)
+			return
+		}
+
+		projects, err := loadProjects()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		tag, _ := cmd.Flags().GetString("tag")
+		projects = filterProjectsByTag(projects, tag)
+		if len(projects) == 0 {
+			fmt.Println("対象のプロジェクトはありません")
+			return
+		}
+
+		// プロジェクトごとに並行して取得し、登録順に表示する
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		statuses := make([]repoStatus, len(projects))
+		forEachProjectParallel(projects, jobs, func(i int, project Project) {
+			statuses[i] = projectRepoStatus(project)
+		})
+
+		if format == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(statuses); err != nil {
+				fmt.Println(err)
+			}
+			return
+		}
+		printRepoStatuses(statuses, time.Now())
+	},
+}
+
+// projectRepoStatus はプロジェクトのディレクトリでGitの状態を取得します
+// モノレポのサブプロジェクトではサブディレクトリ内の変更だけを数えます
+func projectRepoStatus(project Project) repoStatus {
+	status := repoStatus{Project: project.Name, Path: project.Path}
+
+	if _, err := discoverRepo(project.Path); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	output, err := gitOutput(project.Path, "status", "--porcelain=v2", "--branch", "--", ".")
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	parsePorcelainStatus(output, &status)
+
+	// stashがない場合はエラーになるため0件とする
+	if count, err := gitOutput(project.Path, "rev-list", "--walk-reflogs", "--count", "refs/stash"); err == nil {
+		status.Stashes, _ = strconv.Atoi(count)
+	}
+
+	// コミットがない場合は最終コミットを表示しない（サブプロジェクトではサブディレクトリを変更した最後のコミット）
+	if timestamp, err := gitOutput(project.Path, "log", "-1", "--format=%ct", "--", "."); err == nil && timestamp != "" {
+		if seconds, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+			lastCommit := time.Unix(seconds, 0)
+			status.LastCommit = &lastCommit
+		}
+	}
+	return status
+}
+
+// parsePorcelainStatus はgit status --porcelain=v2 --branchの出力を解析します
+func parsePorcelainStatus(output string, status *repoStatus) {
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			status.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			// 形式: # branch.ab +<ahead> -<behind>
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			status.Dirty++
+		case strings.HasPrefix(line, "? "):
+			status.Untracked++
+		}
+	}
+}
+
+// printRepoStatuses は状態を表形式で表示します
+func printRepoStatuses(statuses []repoStatus, now time.Time) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "PROJECT\tBRANCH\tDIRTY\tUNTRACKED\tAHEAD/BEHIND\tSTASH\tLAST COMMIT")
+	var failed []repoStatus
+	for _, status := range statuses {
+		// エラーは列の幅が崩れないように表の後に表示する
+		if status.Error != "" {
+			failed = append(failed, status)
+			continue
+		}
+
+		aheadBehind := "-"
+		if status.Upstream != "" {
+			aheadBehind = fmt.Sprintf("+%d/-%d", status.Ahead, status.Behind)
+		}
+		lastCommit := "-"
+		if status.LastCommit != nil {
+			lastCommit = formatAge(now.Sub(*status.LastCommit))
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%s\t%d\t%s\n",
+			status.Project, status.Branch, status.Dirty, status.Untracked, aheadBehind, status.Stashes, lastCommit)
+	}
+	writer.Flush()
+
+	for _, status := range failed {
+		fmt.Printf("%s: 状態を取得できませんでした: %s\n", status.Project, status.Error)
+	}
+}
+
+// formatAge は経過時間を「3日前」のような形式で返します
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "たった今"
+	case age < time.Hour:
+		return fmt.Sprintf("%d分前", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%d時間前", int(age.Hours()))
+	case age < 30*24*time.Hour:
+		return fmt.Sprintf("%d日前", int(age.Hours()/24))
+	case age < 365*24*time.Hour:
+		return fmt.Sprintf("%dか月前", int(age.Hours()/24/30))
+	default:
+		return fmt.Sprintf("%d年前", int(age.Hours()/24/365))
+	}
+}
+
+func init() {
+	repoCmd.AddCommand(repoStatusCmd)
+	repoStatusCmd.Flags().String("tag", "", "指定したタグが付いたプロジェクトだけを対象にします")
+	repoStatusCmd.Flags().String("format", "table", "出力形式を指定します (table|json)")
+	repoStatusCmd.Flags().Int("jobs", runtime.NumCPU(), "並行して状態を取得するプロジェクト数を指定します")
+}
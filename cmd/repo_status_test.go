@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParsePorcelainStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   repoStatus
+	}{
+		{
+			name:   "clean without upstream",
+			output: "# branch.oid 1234567\n# branch.head main\n",
+			want:   repoStatus{Branch: "main"},
+		},
+		{
+			name: "ahead and behind",
+			output: "# branch.oid 1234567\n# branch.head feature\n# branch.upstream origin/feature\n" +
+				"# branch.ab +3 -2\n",
+			want: repoStatus{Branch: "feature", Upstream: "origin/feature", Ahead: 3, Behind: 2},
+		},
+		{
+			name: "dirty and untracked",
+			output: "# branch.head main\n# branch.upstream origin/main\n# branch.ab +0 -0\n" +
+				"1 .M N... 100644 100644 100644 abc abc src/a.go\n" +
+				"1 A. N... 000000 100644 100644 000 abc src/b.go\n" +
+				"2 R. N... 100644 100644 100644 abc abc R100 src/c.go\tsrc/old.go\n" +
+				"u UU N... 100644 100644 100644 100644 abc abc abc src/d.go\n" +
+				"? notes.txt\n? tmp/\n! ignored.log\n",
+			want: repoStatus{Branch: "main", Upstream: "origin/main", Dirty: 4, Untracked: 2},
+		},
+		{
+			name:   "detached head",
+			output: "# branch.oid 1234567\n# branch.head (detached)\n",
+			want:   repoStatus{Branch: "(detached)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got repoStatus
+			parsePorcelainStatus(tt.output, &got)
+			if got != tt.want {
+				t.Errorf("parsePorcelainStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectRepoStatus(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	project := Project{Name: "web", Path: filepath.Join(root, "packages", "web")}
+	if err := os.MkdirAll(project.Path, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		statusArgs = "status --porcelain=v2 --branch -- ."
+		stashArgs  = "rev-list --walk-reflogs --count refs/stash"
+		logArgs    = "log -1 --format=%ct -- ."
+	)
+	fake := &fakeGitRunner{
+		outputs: map[string]string{
+			statusArgs: "# branch.head main\n# branch.upstream origin/main\n# branch.ab +1 -4\n" +
+				"1 .M N... 100644 100644 100644 abc abc a.go\n? b.go\n",
+			logArgs: "1700000000\n",
+		},
+		errors: map[string]error{stashArgs: errors.New("exit status 128")},
+	}
+	useFakeGit(t, fake)
+
+	got := projectRepoStatus(project)
+	lastCommit := time.Unix(1700000000, 0)
+	want := repoStatus{
+		Project: "web", Path: project.Path, Branch: "main", Upstream: "origin/main",
+		Dirty: 1, Untracked: 1, Ahead: 1, Behind: 4, LastCommit: &lastCommit,
+	}
+	if got.LastCommit == nil || !got.LastCommit.Equal(lastCommit) {
+		t.Errorf("LastCommit = %v, want %v", got.LastCommit, lastCommit)
+	}
+	got.LastCommit, want.LastCommit = nil, nil
+	if got != want {
+		t.Errorf("projectRepoStatus() = %+v, want %+v", got, want)
+	}
+
+	// すべてのコマンドをサブプロジェクトのディレクトリで実行し、変更と最終コミットをその配下に絞り込む
+	var calls []string
+	for _, call := range fake.Calls() {
+		if call.dir != project.Path {
+			t.Errorf("%s を %s で実行しました（want %s）", call, call.dir, project.Path)
+		}
+		calls = append(calls, call.String())
+	}
+	for _, want := range []string{"git " + statusArgs, "git " + logArgs} {
+		if !slices.Contains(calls, want) {
+			t.Errorf("%q を実行していません: %v", want, calls)
+		}
+	}
+}
@@ -0,0 +1,573 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"mei/internal/config"
+	"mei/internal/ignore"
+	"mei/internal/merge"
+)
+
+// templateSuffix は同期時に描画されるテンプレートファイルの拡張子です
+const templateSuffix = ".tmpl"
+
+// conflictPolicy はローカルで変更されたファイルを同期するときの振る舞いを表します
+type conflictPolicy string
+
+const (
+	conflictFail      conflictPolicy = ""          // 同期を中断する（デフォルト）
+	conflictSkip      conflictPolicy = "skip"      // ローカルの変更を残して同期しない
+	conflictOverwrite conflictPolicy = "overwrite" // ローカルの変更を破棄して上書きする
+	conflictBackup    conflictPolicy = "backup"    // ローカルの変更をバックアップしてから上書きする
+	conflictMerge     conflictPolicy = "merge"     // 3-wayマージする
+)
+
+// parseConflictPolicy は--on-conflictフラグの値を検証します
+func parseConflictPolicy(value string) (conflictPolicy, error) {
+	switch policy := conflictPolicy(value); policy {
+	case conflictFail, conflictSkip, conflictOverwrite, conflictBackup, conflictMerge:
+		return policy, nil
+	}
+	return "", fmt.Errorf("不明な--on-conflictの値です: %s (skip, overwrite, backup, merge のいずれか)", value)
+}
+
+// fileSyncer は前回の同期内容を記録しながらファイルをコピーします
+type fileSyncer struct {
+	state      *config.SyncState
+	manifest   *config.SyncManifest
+	onConflict conflictPolicy
+	// conflicts は同期を中断したファイルのパスです
+	conflicts []string
+	// templateData は.tmplファイルの描画に使うデータです
+	templateData *syncTemplateData
+	// links はコピーせずにシンボリックリンクで同期する宛先の絶対パスです
+	links map[string]bool
+	// stats は書き込んだファイルの集計です
+	stats syncStats
+	// snapshot は変更前のファイルの状態です
+	snapshot *config.Snapshot
+	// project は同期中のプロジェクト名です
+	project string
+	// symlinks は同期元にあるシンボリックリンクの扱いです
+	symlinks symlinkPolicy
+	// unitSrc、unitDst はコピー中の同期単位のルートです
+	unitSrc, unitDst string
+	// visiting はコピー中のディレクトリの実体のパスです（リンクの循環検出に使います）
+	visiting map[string]bool
+	// pendingDirs はコピー中でまだ作成していない宛先のディレクトリです（外側から順）
+	// 除外されたファイルしかないディレクトリを作らないよう、最初に書き込むときに作成します
+	pendingDirs []string
+	// dryRun がtrueの場合は書き込まずに変更内容を表示します
+	dryRun bool
+	// checking がtrueの場合は書き込まずに競合だけを記録します
+	checking bool
+	// projects は登録済みプロジェクトとそのリポジトリです（最初に使うときに読み込みます）
+	projects *projectIndex
+}
+
+// syncStats は同期したファイルの集計です
+type syncStats struct {
+	Created   int // 新規作成したファイル数
+	Updated   int // 更新したファイル数
+	Unchanged int // 変更がなかったファイル数
+}
+
+func (s syncStats) String() string {
+	return fmt.Sprintf("作成: %d、更新: %d、変更なし: %d", s.Created, s.Updated, s.Unchanged)
+}
+
+func newFileSyncer(state *config.SyncState, manifest *config.SyncManifest, onConflict conflictPolicy, symlinks symlinkPolicy) *fileSyncer {
+	return &fileSyncer{
+		state:      state,
+		manifest:   manifest,
+		onConflict: onConflict,
+		snapshot:   config.NewSnapshot(),
+		symlinks:   symlinks,
+		visiting:   make(map[string]bool),
+	}
+}
+
+// registeredProjects は登録済みプロジェクトとそのリポジトリを返します
+// 同期の実行中は最初に読み込んだものを使い回します
+func (s *fileSyncer) registeredProjects() (*projectIndex, error) {
+	if s.projects == nil {
+		projects, err := loadProjects()
+		if err != nil {
+			return nil, err
+		}
+		s.projects = newProjectIndex(projects)
+	}
+	return s.projects, nil
+}
+
+// track は変更する前のパスの状態をスナップショットに記録します
+func (s *fileSyncer) track(path string) error {
+	return s.snapshot.Track(s.project, path, s.state)
+}
+
+// trackDir は同期で作成するディレクトリをスナップショットに記録し、復元時に空であれば削除されるようにします
+func (s *fileSyncer) trackDir(dir string) error {
+	return s.snapshot.TrackDir(s.project, dir)
+}
+
+// saveSnapshot は今回の同期で変更したファイルのスナップショットを保存し、次の実行に備えて新しくします
+func (s *fileSyncer) saveSnapshot() error {
+	saved, err := s.snapshot.Save()
+	if err != nil {
+		return err
+	}
+	if saved {
+		fmt.Printf("変更前の状態を保存しました: %s（mei project restore %s で元に戻せます）\n", s.snapshot.ID, s.snapshot.ID)
+	}
+	s.snapshot = config.NewSnapshot()
+	return nil
+}
+
+// beginProject はプロジェクトごとの状態を設定します
+func (s *fileSyncer) beginProject(project Project) {
+	s.project = project.Name
+	s.templateData = newSyncTemplateData(project)
+	s.links = projectLinks(project)
+	s.stats = syncStats{}
+	s.conflicts = nil
+}
+
+// takeConflicts は記録された競合を返してリセットします
+func (s *fileSyncer) takeConflicts() []string {
+	conflicts := s.conflicts
+	s.conflicts = nil
+	return conflicts
+}
+
+// copyUnit は同期単位（cursor、github）のディレクトリを、.meiignoreとsync.ymlの設定に従ってコピーします
+func (s *fileSyncer) copyUnit(unit, src, dst string) error {
+	var err error
+	if s.unitSrc, err = filepath.Abs(src); err != nil {
+		return err
+	}
+	if s.unitDst, err = filepath.Abs(dst); err != nil {
+		return err
+	}
+	return s.copyChecked(func() error {
+		return s.copyDir(src, dst, s.unitFilter(unit), ".")
+	})
+}
+
+// copyUnitFS は同期単位の組み込みのデフォルトをsync.ymlの設定に従ってコピーします
+func (s *fileSyncer) copyUnitFS(unit string, fsys fs.FS, dst string) error {
+	return s.copyChecked(func() error {
+		return s.copyFS(fsys, dst, s.unitFilter(unit))
+	})
+}
+
+// copyChecked はcopyで同期単位をコピーします
+// 競合時に中断する場合は、同期単位の一部だけが書き込まれないように先に書き込まずに競合を確認し、
+// 競合があれば何も書き込みません
+func (s *fileSyncer) copyChecked(copy func() error) error {
+	if s.onConflict == conflictFail && !s.dryRun {
+		s.checking = true
+		err := copy()
+		s.checking = false
+		s.pendingDirs = nil
+		if err != nil || len(s.conflicts) > 0 {
+			return err
+		}
+	}
+	return copy()
+}
+
+// unitFilter は同期単位のFilterを作成します
+func (s *fileSyncer) unitFilter(unit string) *ignore.Filter {
+	settings := s.manifest.Units[unit]
+	return ignore.New(append(append([]string(nil), ignore.DefaultPatterns...), settings.Exclude...), settings.Include)
+}
+
+// copyDir はディレクトリを再帰的にコピーします
+// relは同期単位のルートからの相対パスで、filterの判定に使います
+func (s *fileSyncer) copyDir(src, dst string, filter *ignore.Filter, rel string) error {
+	dst, err := filepath.Abs(dst)
+	if err != nil {
+		return err
+	}
+	if s.links[dst] {
+		return s.linkPath(src, dst)
+	}
+
+	// リンクをたどってコピーする場合に循環しないようにする
+	realSrc, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return err
+	}
+	if s.visiting[realSrc] {
+		s.warnf("シンボリックリンクが循環しているためスキップしました: %s\n", src)
+		return nil
+	}
+	s.visiting[realSrc] = true
+	defer delete(s.visiting, realSrc)
+
+	// 対象ディレクトリは最初にファイルを書き込むときに作成する
+	// 宛先がシンボリックリンクの場合はそのときにリンク先に書き込まないように実体に置き換える
+	s.deferDir(dst)
+
+	// ソースディレクトリ内のファイルとディレクトリを取得
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	// .meiignoreがあればこのディレクトリ配下の除外パターンに追加
+	if content, err := os.ReadFile(filepath.Join(src, ignore.FileName)); err == nil {
+		filter = filter.WithIgnoreFile(rel, string(content))
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+		entryRel := filepath.ToSlash(filepath.Join(rel, entry.Name()))
+
+		if filter.Excluded(entryRel, entry.IsDir()) {
+			continue
+		}
+
+		if s.links[dstPath] {
+			// シンボリックリンクで同期する場合
+			err = s.linkPath(srcPath, dstPath)
+			if err != nil {
+				return err
+			}
+		} else if entry.Type()&os.ModeSymlink != 0 {
+			// 同期元のシンボリックリンクは--symlinksの設定に従う
+			err = s.copySymlink(srcPath, dstPath, filter, entryRel)
+			if err != nil {
+				return err
+			}
+		} else if !entry.IsDir() && !entry.Type().IsRegular() {
+			// 名前付きパイプやソケットなどは読み込むと止まることがあるためコピーしない
+			s.warnf("通常のファイルではないためスキップしました: %s\n", srcPath)
+		} else if entry.IsDir() {
+			// サブディレクトリの場合は再帰的にコピー
+			err = s.copyDir(srcPath, dstPath, filter, entryRel)
+			if err != nil {
+				return err
+			}
+		} else if strings.HasSuffix(entry.Name(), templateSuffix) {
+			// テンプレートの場合は描画して拡張子を外す
+			err = s.renderFile(srcPath, strings.TrimSuffix(dstPath, templateSuffix))
+			if err != nil {
+				return err
+			}
+		} else {
+			// ファイルの場合はコピー
+			err = s.copyFile(srcPath, dstPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// deferDir は宛先のディレクトリを、その配下に最初に書き込むときに作成するよう予約します
+func (s *fileSyncer) deferDir(dir string) {
+	s.prunePendingDirs(dir)
+	s.pendingDirs = append(s.pendingDirs, dir)
+}
+
+// createDirs はpathを含む予約済みのディレクトリを作成します
+// 宛先のディレクトリがシンボリックリンクの場合はリンク先に書き込まないように実体に置き換えます
+func (s *fileSyncer) createDirs(path string) error {
+	s.prunePendingDirs(path)
+	pending := s.pendingDirs
+	s.pendingDirs = nil
+	if s.dryRun {
+		return nil
+	}
+	for _, dir := range pending {
+		if err := s.replaceSymlink(dir); err != nil {
+			return err
+		}
+		if err := s.trackDir(dir); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prunePendingDirs はpathを含まない予約を、書き込むファイルがなかったものとして取り消します
+func (s *fileSyncer) prunePendingDirs(path string) {
+	kept := s.pendingDirs[:0]
+	for _, dir := range s.pendingDirs {
+		if containsPath(dir, path) {
+			kept = append(kept, dir)
+		}
+	}
+	s.pendingDirs = kept
+}
+
+// copyFS は組み込みのファイルシステムの内容をコピーします
+func (s *fileSyncer) copyFS(fsys fs.FS, dst string, filter *ignore.Filter) error {
+	dst, err := filepath.Abs(dst)
+	if err != nil {
+		return err
+	}
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(dst, filepath.FromSlash(path))
+
+		if path != "." && filter.Excluded(path, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			s.deferDir(destPath)
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		return s.writeFile(destPath, data, false)
+	})
+}
+
+// copyFile はファイルをコピーします
+func (s *fileSyncer) copyFile(src, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	return s.writeFile(dst, content, isExecutable(srcInfo.Mode()))
+}
+
+// renderFile はテンプレートファイルを描画して書き込みます
+func (s *fileSyncer) renderFile(src, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New(filepath.Base(src)).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("テンプレートの解析に失敗しました (%s): %w", src, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s.templateData); err != nil {
+		return fmt.Errorf("テンプレートの実行に失敗しました (%s): %w", src, err)
+	}
+
+	return s.writeFile(dst, buf.Bytes(), isExecutable(srcInfo.Mode()))
+}
+
+// writeFile は内容を書き込みます
+// 前回の同期以降に宛先が変更されている場合はonConflictに従います
+// 権限は新規作成時にumaskを適用し、既存のファイルは実行権限の有無だけを同期元に合わせます
+func (s *fileSyncer) writeFile(dst string, content []byte, executable bool) error {
+	dst, err := filepath.Abs(dst)
+	if err != nil {
+		return err
+	}
+	if s.checking {
+		return s.checkFile(dst, content)
+	}
+	if err := s.createDirs(dst); err != nil {
+		return err
+	}
+	if err := s.track(dst); err != nil {
+		return err
+	}
+	if s.dryRun {
+		return s.planFile(dst, content)
+	}
+	if err := s.replaceSymlink(dst); err != nil {
+		return err
+	}
+
+	data := content
+	local, err := os.ReadFile(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exists := err == nil
+
+	if s.modifiedLocally(dst, local, exists, content) {
+		switch s.onConflict {
+		case conflictFail:
+			s.conflicts = append(s.conflicts, dst)
+			return nil
+		case conflictSkip:
+			fmt.Printf("警告: ローカルで変更されているためスキップしました: %s\n", dst)
+			return nil
+		case conflictOverwrite:
+			fmt.Printf("警告: ローカルの変更を上書きしました: %s\n", dst)
+		case conflictBackup:
+			backupPath := backupPathFor(dst)
+			if err := s.track(backupPath); err != nil {
+				return err
+			}
+			if err := config.WriteFileAtomic(backupPath, local, 0644); err != nil {
+				return fmt.Errorf("バックアップの作成に失敗しました: %w", err)
+			}
+			fmt.Printf("ローカルの変更をバックアップしました: %s\n", backupPath)
+		case conflictMerge:
+			base, err := s.state.LoadBlob(s.state.Files[dst])
+			if err != nil {
+				s.conflicts = append(s.conflicts, dst)
+				return nil
+			}
+			merged, clean := merge.Merge(string(base), string(local), string(content))
+			if !clean {
+				fmt.Printf("警告: マージ結果に競合マーカーが含まれています: %s\n", dst)
+			}
+			data = []byte(merged)
+		}
+	}
+
+	// 内容が同じ場合は書き込まずにmtimeを保つ（実行権限のみ異なる場合は権限だけ更新する）
+	if exists && bytes.Equal(local, data) {
+		updated, err := syncExecutable(dst, executable)
+		if err != nil {
+			return err
+		}
+		if updated {
+			s.stats.Updated++
+		} else {
+			s.stats.Unchanged++
+		}
+		return s.state.Record(dst, content)
+	}
+
+	// 一時ファイル経由で書き込み、中断されても書きかけのファイルが残らないようにする
+	perm := os.FileMode(0666)
+	if executable {
+		perm = 0777
+	}
+	if err := config.WriteFileAtomic(dst, data, perm); err != nil {
+		return err
+	}
+	if _, err := syncExecutable(dst, executable); err != nil {
+		return err
+	}
+	if exists {
+		s.stats.Updated++
+	} else {
+		s.stats.Created++
+	}
+
+	// マージした場合も同期元の内容を記録し、次回のマージの共通祖先とする
+	return s.state.Record(dst, content)
+}
+
+// modifiedLocally は宛先が前回の同期以降にローカルで変更され、同期する内容とも異なるかを返します
+// 記録がないファイルは以前のバージョンが書き込んだものとみなして上書きします
+func (s *fileSyncer) modifiedLocally(dst string, local []byte, exists bool, content []byte) bool {
+	recorded, ok := s.state.Files[dst]
+	return exists && ok && config.HashContent(local) != recorded && !bytes.Equal(local, content)
+}
+
+// checkFile は宛先がローカルで変更されていれば競合として記録します
+func (s *fileSyncer) checkFile(dst string, content []byte) error {
+	local, err := os.ReadFile(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if s.modifiedLocally(dst, local, err == nil, content) {
+		s.conflicts = append(s.conflicts, dst)
+	}
+	return nil
+}
+
+// planFile はdry-runでwriteFileが行う変更を表示します
+func (s *fileSyncer) planFile(dst string, content []byte) error {
+	local, err := os.ReadFile(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exists := err == nil
+
+	switch {
+	case exists && bytes.Equal(local, content):
+		s.stats.Unchanged++
+	case s.modifiedLocally(dst, local, exists, content):
+		fmt.Printf("%s ローカルで変更されています（--on-conflictに従います）: %s\n", dryRunPrefix, dst)
+		s.stats.Updated++
+	case exists:
+		fmt.Printf("%s 更新します: %s\n", dryRunPrefix, dst)
+		s.stats.Updated++
+	default:
+		fmt.Printf("%s 作成します: %s\n", dryRunPrefix, dst)
+		s.stats.Created++
+	}
+	return nil
+}
+
+// warnf はコピー中の警告を表示します
+// 競合の確認中は続けて行うコピーで同じ警告を表示するため表示しません
+func (s *fileSyncer) warnf(format string, args ...any) {
+	if !s.checking {
+		fmt.Printf("警告: "+format, args...)
+	}
+}
+
+// isExecutable は実行権限を持つかを返します
+func isExecutable(mode os.FileMode) bool {
+	return mode.Perm()&0111 != 0
+}
+
+// syncExecutable はファイルの実行権限の有無をexecutableに合わせ、変更したかを返します
+// 実行権限は読み取り権限のある対象（所有者・グループ・その他）にだけ付与します
+func syncExecutable(path string, executable bool) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	mode := info.Mode().Perm()
+	if isExecutable(mode) == executable {
+		return false, nil
+	}
+
+	if executable {
+		mode |= (mode & 0444) >> 2
+	} else {
+		mode &^= 0111
+	}
+	return true, os.Chmod(path, mode)
+}
+
+// backupPathFor はローカルの変更を退避するパスを返します
+func backupPathFor(path string) string {
+	return fmt.Sprintf("%s.%s.bak", path, time.Now().Format("20060102150405"))
+}
+
+// formatConflicts は競合したファイルの一覧をエラーにします
+func formatConflicts(conflicts []string) error {
+	return fmt.Errorf("ローカルで変更されたファイルがあるため同期を中断しました（--on-conflict=skip|overwrite|backup|merge で動作を指定できます）:\n  %s",
+		strings.Join(conflicts, "\n  "))
+}
@@ -0,0 +1,204 @@
+//go:build unix
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// buildFuzzTree はdataを操作の列として解釈し、root配下に同期元のツリーを作成します
+// 循環するリンク、壊れたリンク、outsideを指すリンク、名前付きパイプ、実行権限や読み取り専用のファイルを含みます
+func buildFuzzTree(t *testing.T, data []byte, root, outside string) {
+	t.Helper()
+	dirs := []string{root}
+	var nodes []string
+	next := func() byte {
+		if len(data) == 0 {
+			return 0
+		}
+		b := data[0]
+		data = data[1:]
+		return b
+	}
+
+	for i := 0; len(data) > 0 && i < 64; i++ {
+		op := next()
+		parent := dirs[int(next())%len(dirs)]
+		path := filepath.Join(parent, fmt.Sprintf("n%d", i))
+
+		var err error
+		switch op % 8 {
+		case 0, 1:
+			// 通常のファイル（実行権限や読み取り専用を含む）
+			perm := []os.FileMode{0644, 0755, 0444, 0555}[int(next())%4]
+			err = os.WriteFile(path, bytes.Repeat([]byte{op}, int(next())%16), perm)
+			if err == nil {
+				err = os.Chmod(path, perm)
+			}
+			nodes = append(nodes, path)
+		case 2, 3:
+			err = os.Mkdir(path, 0755)
+			dirs = append(dirs, path)
+			nodes = append(nodes, path)
+		case 4:
+			// ツリー内のディレクトリ（祖先の場合は循環する）やファイルを指すリンク
+			candidates := append(append([]string(nil), dirs...), nodes...)
+			target := candidates[int(next())%len(candidates)]
+			rel, relErr := filepath.Rel(parent, target)
+			if relErr != nil {
+				t.Fatal(relErr)
+			}
+			err = os.Symlink(rel, path)
+		case 5:
+			err = os.Symlink(filepath.Join(root, "missing"), path)
+		case 6:
+			err = os.Symlink(outside, path)
+		case 7:
+			err = syscall.Mkfifo(path, 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkCopiedTree はdstの内容がsrcのコピーになっていることを確認します
+func checkCopiedTree(t *testing.T, src, dst string, policy symlinkPolicy) {
+	t.Helper()
+
+	// 宛先の通常のファイルは同期元（リンクをたどった先）と同じ内容と実行権限を持つ
+	err := filepath.WalkDir(dst, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dst, path)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(d.Name(), ".mei-tmp-") {
+			t.Errorf("一時ファイルが残っています: %s", rel)
+		}
+		switch {
+		case d.IsDir():
+			return nil
+		case d.Type()&os.ModeSymlink != 0:
+			if policy != symlinkPreserve {
+				t.Errorf("--symlinks=%s でリンクが作成されました: %s", policy, rel)
+			}
+			return nil
+		case !d.Type().IsRegular():
+			t.Errorf("通常のファイルではないものがコピーされました: %s（%v）", rel, d.Type())
+			return nil
+		}
+
+		srcPath := filepath.Join(src, rel)
+		srcInfo, err := os.Stat(srcPath)
+		if err != nil {
+			t.Errorf("同期元にない %s がコピーされました: %v", rel, err)
+			return nil
+		}
+		want, err := os.ReadFile(srcPath)
+		if err != nil {
+			return err
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s の内容が %q です（want %q）", rel, got, want)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if isExecutable(info.Mode()) != isExecutable(srcInfo.Mode()) {
+			t.Errorf("%s の権限が %v です（同期元 %v）", rel, info.Mode(), srcInfo.Mode())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// リンクを含まない同期元の通常のファイルとリンクはすべて宛先にある
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.Type().IsRegular():
+			if _, err := os.Stat(filepath.Join(dst, rel)); err != nil {
+				t.Errorf("%s がコピーされていません: %v", rel, err)
+			}
+		case d.Type()&os.ModeSymlink != 0 && policy == symlinkPreserve:
+			if _, err := os.Lstat(filepath.Join(dst, rel)); err != nil {
+				t.Errorf("リンク %s が作成されていません: %v", rel, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func FuzzCopyDir(f *testing.F) {
+	f.Add([]byte{0, 0, 5, 3, 2, 0, 1, 1, 2, 7, 0})
+	// サブディレクトリから祖先を指すリンク（循環）
+	f.Add([]byte{2, 0, 2, 1, 4, 2, 0, 0, 1, 1, 9})
+	// 壊れたリンク、外を指すリンク、名前付きパイプ
+	f.Add([]byte{5, 0, 6, 0, 7, 0, 2, 0, 6, 3, 7, 3})
+	// 実行権限と読み取り専用のファイル、ファイルへのリンク
+	f.Add([]byte{0, 0, 1, 4, 1, 0, 2, 8, 0, 0, 3, 3, 4, 0, 0})
+	f.Add([]byte("mei sync copy"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		outside := t.TempDir()
+		if err := os.WriteFile(filepath.Join(outside, "outside.txt"), []byte("outside"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, policy := range []symlinkPolicy{symlinkPreserve, symlinkFollow, symlinkSkip} {
+			src := filepath.Join(t.TempDir(), "src")
+			if err := os.Mkdir(src, 0755); err != nil {
+				t.Fatal(err)
+			}
+			buildFuzzTree(t, data, src, outside)
+			dst := filepath.Join(t.TempDir(), "dst")
+
+			syncer := newCopySyncer(t)
+			syncer.symlinks = policy
+			if err := syncer.copyUnit("cursor", src, dst); err != nil {
+				t.Fatalf("--symlinks=%s: %v", policy, err)
+			}
+			if conflicts := syncer.takeConflicts(); len(conflicts) > 0 {
+				t.Fatalf("--symlinks=%s: 競合しました: %v", policy, conflicts)
+			}
+			if _, err := os.Stat(dst); os.IsNotExist(err) {
+				continue
+			}
+			checkCopiedTree(t, src, dst, policy)
+
+			// 2回目の同期では何も書き込まない
+			syncer.stats = syncStats{}
+			if err := syncer.copyUnit("cursor", src, dst); err != nil {
+				t.Fatalf("--symlinks=%s の2回目: %v", policy, err)
+			}
+			if syncer.stats.Created != 0 || syncer.stats.Updated != 0 {
+				t.Errorf("--symlinks=%s の2回目の同期で書き込みました: %s", policy, syncer.stats)
+			}
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"mei/internal/config"
+)
+
+// newCopySyncer はHOMEを一時ディレクトリに差し替えて、ファイルのコピーに使う同期処理を作成します
+func newCopySyncer(t *testing.T) *fileSyncer {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	syncer := newFileSyncer(config.NewSyncState(), &config.SyncManifest{}, conflictFail, symlinkPreserve)
+	syncer.beginProject(Project{Name: "test"})
+	return syncer
+}
+
+// listTree はroot配下のパスを「/」区切りで、ディレクトリには末尾に「/」を付けて返します
+func listTree(t *testing.T, root string) []string {
+	t.Helper()
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			rel += "/"
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func TestCopyUnitSkipsEmptyDirs(t *testing.T) {
+	syncer := newCopySyncer(t)
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, ".meiignore"), "*.log\n/drafts/\n")
+	writeTestFile(t, filepath.Join(src, "rules", "a.mdc"), "a")
+	writeTestFile(t, filepath.Join(src, "rules", "debug.log"), "log")
+	writeTestFile(t, filepath.Join(src, "logs", "x.log"), "log")
+	writeTestFile(t, filepath.Join(src, "logs", "deep", "y.log"), "log")
+	writeTestFile(t, filepath.Join(src, "drafts", "b.mdc"), "b")
+	writeTestFile(t, filepath.Join(src, "private", ".meiignore"), "*\n")
+	writeTestFile(t, filepath.Join(src, "private", "secret.mdc"), "s")
+	if err := os.MkdirAll(filepath.Join(src, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), ".cursor")
+	if err := syncer.copyUnit("cursor", src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"rules/", "rules/a.mdc"}
+	if got := listTree(t, dst); !slices.Equal(got, want) {
+		t.Errorf("コピーされたパス = %v; want %v", got, want)
+	}
+}
+
+func TestCopyUnitAllExcluded(t *testing.T) {
+	syncer := newCopySyncer(t)
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, ".meiignore"), "*\n")
+	writeTestFile(t, filepath.Join(src, "a.txt"), "a")
+
+	dst := filepath.Join(t.TempDir(), ".github")
+	if err := syncer.copyUnit("github", src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(dst); !os.IsNotExist(err) {
+		t.Errorf("すべて除外された同期単位の宛先 %s が作成されました", dst)
+	}
+}
+
+func TestCopyUnitFSSkipsEmptyDirs(t *testing.T) {
+	syncer := newCopySyncer(t)
+	syncer.manifest.Units = map[string]config.SyncUnit{"cursor": {Exclude: []string{"*.log"}}}
+	fsys := fstest.MapFS{
+		"rules/a.mdc":    {Data: []byte("a")},
+		"logs/x.log":     {Data: []byte("log")},
+		"logs/deep/y.md": {Data: []byte("y")},
+		"logs/old/z.log": {Data: []byte("log")},
+	}
+
+	dst := filepath.Join(t.TempDir(), ".cursor")
+	if err := syncer.copyUnitFS("cursor", fsys, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"logs/", "logs/deep/", "logs/deep/y.md", "rules/", "rules/a.mdc"}
+	if got := listTree(t, dst); !slices.Equal(got, want) {
+		t.Errorf("コピーされたパス = %v; want %v", got, want)
+	}
+}
+
+func TestCopyUnitReplacesSymlinkedDir(t *testing.T) {
+	syncer := newCopySyncer(t)
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "rules", "a.mdc"), "a")
+
+	// 宛先のディレクトリがリンクの場合はリンク先に書き込まない
+	outside := t.TempDir()
+	dst := filepath.Join(t.TempDir(), ".cursor")
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dst, "rules")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := syncer.copyUnit("cursor", src, dst); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(filepath.Join(dst, "rules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.IsDir() {
+		t.Errorf("%s がディレクトリに置き換えられていません（%v）", filepath.Join(dst, "rules"), info.Mode())
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("リンク先に書き込まれました: %v", entries)
+	}
+}
+
+func TestCopyUnitConflictWritesNothing(t *testing.T) {
+	syncer := newCopySyncer(t)
+	src := t.TempDir()
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "a.mdc"), "a1")
+	writeTestFile(t, filepath.Join(src, "z.mdc"), "z1")
+	if err := syncer.copyUnit("cursor", src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同期元を更新し、後にコピーされるファイルだけをローカルで変更する
+	writeTestFile(t, filepath.Join(src, "a.mdc"), "a2")
+	writeTestFile(t, filepath.Join(src, "new", "b.mdc"), "b")
+	writeTestFile(t, filepath.Join(src, "z.mdc"), "z2")
+	writeTestFile(t, filepath.Join(dst, "z.mdc"), "local")
+
+	if err := syncer.copyUnit("cursor", src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := syncer.takeConflicts(), []string{filepath.Join(dst, "z.mdc")}; !slices.Equal(got, want) {
+		t.Errorf("conflicts = %v, want %v", got, want)
+	}
+	if got, want := listTree(t, dst), []string{"a.mdc", "z.mdc"}; !slices.Equal(got, want) {
+		t.Errorf("tree = %v, want %v", got, want)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dst, "a.mdc")); string(content) != "a1" {
+		t.Errorf("a.mdc = %q, want unchanged %q", content, "a1")
+	}
+}
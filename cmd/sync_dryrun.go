@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"mei/internal/config"
+)
+
+// dryRunPrefix はdry-runで実行しなかった変更を表示するときの接頭辞です
+const dryRunPrefix = "[dry-run]"
+
+// mkdirAll はディレクトリを作成します（dryRunの場合は何もしません）
+// 作成したディレクトリは復元時に空であれば削除されるようにスナップショットに記録します
+func (s *fileSyncer) mkdirAll(dir string) error {
+	if s.dryRun {
+		return nil
+	}
+	if err := s.trackDir(dir); err != nil {
+		return err
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// updateBlock はファイルのブロックを更新します
+// dryRunの場合は変更があるかだけを表示します
+func (s *fileSyncer) updateBlock(blockManager *config.BlockManager, path string) error {
+	if !s.dryRun {
+		return blockManager.UpdateFile(path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if blockManager.Apply(string(content)) != string(content) {
+		fmt.Printf("%s %s のブロック %s を更新します\n", dryRunPrefix, path, blockManager.Label)
+	}
+	return nil
+}
+
+// runGit は設定を変更するGitコマンドを実行します
+// dryRunの場合は実行するコマンドを表示するだけです
+func (s *fileSyncer) runGit(workTree string, args ...string) error {
+	if s.dryRun {
+		fmt.Printf("%s git %s\n", dryRunPrefix, strings.Join(args, " "))
+		return nil
+	}
+	return runGitCommand(workTree, args...)
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mei/internal/config"
+	"mei/internal/gitrepo"
+)
+
+// hookBlockLabel は~/.mei/git/hooksから配布したフックのブロックのラベルです
+const hookBlockLabel = "mei:hook"
+
+// defaultShebang はシェバンのないフックを実行するシェルです（gitはshで実行します）
+const defaultShebang = "#!/bin/sh"
+
+// shellInterpreters はフックのブロックを直接書き込めるシェルです
+var shellInterpreters = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true}
+
+// syncHooks は~/.mei/git/hooks/<hook>を.git/hooks/<hook>にブロックとして書き込みます
+// 既存のフックの内容は残し、無効になったフックや削除されたフックのブロックは取り除きます
+func syncHooks(project Project, syncer *fileSyncer, repo *gitrepo.Repo, meiDir string) error {
+	sourceDir := filepath.Join(meiDir, "git", "hooks")
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("フックの読み込みに失敗しました: %w", err)
+	}
+
+	// linked worktreeでもフックはメインのgitディレクトリのものが使われる
+	hooksDir := filepath.Join(repo.CommonDir, "hooks")
+
+	// フックはリポジトリで共有されるため、同じリポジトリのサブプロジェクトのどれかで有効なら配布する
+	candidates := []Project{project}
+	projects, err := syncer.registeredProjects()
+	if err != nil {
+		return err
+	}
+	for _, sibling := range projects.siblings(repo) {
+		candidates = append(candidates, sibling.Project)
+	}
+	enabled := func(hook string) bool {
+		for _, candidate := range candidates {
+			if syncer.manifest.HookEnabled(hook, candidate.Name, candidate.Tags) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var installed []string
+	distributed := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !enabled(name) {
+			continue
+		}
+		script, err := os.ReadFile(filepath.Join(sourceDir, name))
+		if err != nil {
+			return fmt.Errorf("フック %s の読み込みに失敗しました: %w", name, err)
+		}
+		ok, err := syncer.installHook(filepath.Join(hooksDir, name), name, string(script))
+		if err != nil {
+			return fmt.Errorf("フック %s の書き込みに失敗しました: %w", name, err)
+		}
+		if ok {
+			installed = append(installed, name)
+		}
+		distributed[name] = true
+	}
+
+	// 配布しなくなったフックのブロックを取り除く
+	hookFiles, err := os.ReadDir(hooksDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, hookFile := range hookFiles {
+		name := hookFile.Name()
+		if hookFile.IsDir() || distributed[name] || strings.HasSuffix(name, ".sample") {
+			continue
+		}
+		if err := syncer.removeHook(filepath.Join(hooksDir, name), name); err != nil {
+			return fmt.Errorf("フック %s の削除に失敗しました: %w", name, err)
+		}
+	}
+
+	if len(installed) > 0 {
+		fmt.Printf("%s のフックを更新しました（%s）\n", project.Name, strings.Join(installed, ", "))
+	}
+	return nil
+}
+
+// installHook はフックのスクリプトを.git/hooks/<hook>のブロックにします
+// 既存のフックと同じシェルで実行できる場合はサブシェルとして埋め込み、そうでない場合は
+// シェバンを保ったまま.git/hooks/mei/<hook>に書き出してブロックから呼び出します
+// 既存のフックがシェルスクリプトでなく書き込めない場合はfalseを返します
+func (s *fileSyncer) installHook(hookPath, name, script string) (bool, error) {
+	if err := s.track(hookPath); err != nil {
+		return false, err
+	}
+
+	// 新しく作成するフックはスクリプトと同じシェル（シェル以外のスクリプトはsh）で実行する
+	sourceShebang, body := splitShebang(script)
+	targetShebang := sourceShebang
+	if !shellInterpreters[shebangInterpreter(sourceShebang)] {
+		targetShebang = defaultShebang
+	}
+	current, err := os.ReadFile(hookPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	exists := err == nil
+	if exists {
+		targetShebang, _ = splitShebang(string(current))
+	}
+
+	source, target := shebangInterpreter(sourceShebang), shebangInterpreter(targetShebang)
+	if !shellInterpreters[target] {
+		fmt.Printf("警告: %s はシェルスクリプトではないため、フック %s を書き込みませんでした\n", hookPath, name)
+		return false, nil
+	}
+
+	scriptPath := filepath.Join(filepath.Dir(hookPath), "mei", name)
+	var content string
+	if source == target || source == "sh" {
+		// exitで既存のフックの処理が止まらないようにサブシェルで実行する（標準入力と引数は引き継がれる）
+		if !strings.HasSuffix(body, "\n") {
+			body += "\n"
+		}
+		content = "(\n" + body + ") || exit $?\n"
+		if err := s.removeHookScript(scriptPath); err != nil {
+			return false, err
+		}
+	} else {
+		if err := s.mkdirAll(filepath.Dir(scriptPath)); err != nil {
+			return false, err
+		}
+		if err := s.writeFile(scriptPath, []byte(script), true); err != nil {
+			return false, err
+		}
+		content = fmt.Sprintf("\"$(dirname \"$0\")/mei/%s\" \"$@\" || exit $?\n", name)
+	}
+
+	if !exists {
+		if s.dryRun {
+			fmt.Printf("%s 作成します: %s\n", dryRunPrefix, hookPath)
+		} else {
+			if err := s.mkdirAll(filepath.Dir(hookPath)); err != nil {
+				return false, err
+			}
+			if err := config.WriteFileAtomic(hookPath, []byte(targetShebang+"\n"), 0755); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	blockManager := config.NewBlockManager(hookBlockLabel, content, "#")
+	if err := s.updateBlock(blockManager, hookPath); err != nil {
+		return false, err
+	}
+	if s.dryRun {
+		return true, nil
+	}
+	// gitは実行権限のないフックを無視する
+	_, err = syncExecutable(hookPath, true)
+	return true, err
+}
+
+// removeHook は配布しなくなったフックのブロックを取り除きます
+func (s *fileSyncer) removeHook(hookPath, name string) error {
+	content, err := os.ReadFile(hookPath)
+	if err != nil {
+		return nil
+	}
+	blockManager := config.NewBlockManager(hookBlockLabel, "", "#")
+	if !blockManager.Contains(string(content)) {
+		return nil
+	}
+
+	if err := s.track(hookPath); err != nil {
+		return err
+	}
+	if s.dryRun {
+		fmt.Printf("%s %s からフックのブロックを取り除きます\n", dryRunPrefix, hookPath)
+	} else if err := blockManager.RemoveFromFile(hookPath); err != nil {
+		return err
+	}
+	if err := s.removeHookScript(filepath.Join(filepath.Dir(hookPath), "mei", name)); err != nil {
+		return err
+	}
+	fmt.Printf("配布しなくなったフック %s を取り除きました\n", name)
+	return nil
+}
+
+// removeHookScript は.git/hooks/mei/に書き出したフックのスクリプトを削除します
+func (s *fileSyncer) removeHookScript(scriptPath string) error {
+	if _, err := os.Lstat(scriptPath); err != nil {
+		return nil
+	}
+	if err := s.track(scriptPath); err != nil {
+		return err
+	}
+	if s.dryRun {
+		fmt.Printf("%s 削除します: %s\n", dryRunPrefix, scriptPath)
+		return nil
+	}
+	return os.Remove(scriptPath)
+}
+
+// splitShebang はスクリプトをシェバンの行とそれ以降に分けます
+// シェバンがない場合はgitと同じくshで実行されるものとみなします
+func splitShebang(script string) (string, string) {
+	if !strings.HasPrefix(script, "#!") {
+		return defaultShebang, script
+	}
+	shebang, body, _ := strings.Cut(script, "\n")
+	return strings.TrimSpace(shebang), body
+}
+
+// shebangInterpreter はシェバンのインタプリタ名を返します（#!/usr/bin/env bash の場合はbash）
+func shebangInterpreter(shebang string) string {
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		return "sh"
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				return filepath.Base(field)
+			}
+		}
+	}
+	return interpreter
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mei/internal/config"
+	"mei/internal/gitrepo"
+)
+
+// newHookTestRepo は.git/hooksを持つリポジトリと、それだけを含むプロジェクトを同期する同期処理を作成します
+func newHookTestRepo(t *testing.T) (*fileSyncer, Project, *gitrepo.Repo) {
+	t.Helper()
+	workTree := t.TempDir()
+	gitDir := filepath.Join(workTree, ".git")
+	if err := os.MkdirAll(filepath.Join(gitDir, "hooks"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo := &gitrepo.Repo{WorkTree: workTree, GitDir: gitDir, CommonDir: gitDir}
+	project := Project{Name: "app", Path: workTree}
+	return newTestSyncer(t, []Project{project}, repo), project, repo
+}
+
+// syncGitHooks はsyncHooksを実行し、出力を捨てます
+func syncGitHooks(t *testing.T, syncer *fileSyncer, project Project, repo *gitrepo.Repo) {
+	t.Helper()
+	meiDir, err := meiHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() {
+		if err := syncHooks(project, syncer, repo, meiDir); err != nil {
+			t.Fatalf("syncHooks() error = %v", err)
+		}
+	})
+}
+
+func readHook(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		t.Errorf("%s に実行権限がありません（%v）", path, info.Mode().Perm())
+	}
+	return string(content)
+}
+
+func TestSyncHooksExistingHook(t *testing.T) {
+	const handWritten = "#!/bin/bash\nset -e\nnpm run lint\n"
+	tests := []struct {
+		name       string
+		source     string // ~/.mei/git/hooks/pre-commitの内容
+		block      string // pre-commitに書き込まれるブロックの内容
+		wantScript string // .git/hooks/mei/pre-commitの内容（空の場合は作成されない）
+	}{
+		{
+			name:   "sh script is embedded",
+			source: "#!/bin/sh\necho mei\n",
+			block:  "(\necho mei\n) || exit $?\n",
+		},
+		{
+			name:   "script without shebang is embedded",
+			source: "echo mei",
+			block:  "(\necho mei\n) || exit $?\n",
+		},
+		{
+			name:       "other interpreter is called from the block",
+			source:     "#!/usr/bin/env python3\nprint('mei')\n",
+			block:      "\"$(dirname \"$0\")/mei/pre-commit\" \"$@\" || exit $?\n",
+			wantScript: "#!/usr/bin/env python3\nprint('mei')\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer, project, repo := newHookTestRepo(t)
+			hookPath := filepath.Join(repo.CommonDir, "hooks", "pre-commit")
+			scriptPath := filepath.Join(repo.CommonDir, "hooks", "mei", "pre-commit")
+			if err := os.WriteFile(hookPath, []byte(handWritten), 0755); err != nil {
+				t.Fatal(err)
+			}
+			source := filepath.Join(os.Getenv("HOME"), ".mei", "git", "hooks", "pre-commit")
+			writeTestFile(t, source, tt.source)
+
+			syncGitHooks(t, syncer, project, repo)
+			want := config.NewBlockManager(hookBlockLabel, tt.block, "#").Apply(handWritten)
+			if got := readHook(t, hookPath); got != want {
+				t.Errorf("pre-commit = %q, want %q", got, want)
+			}
+			script, err := os.ReadFile(scriptPath)
+			if tt.wantScript == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("%s が作成されています: %v", scriptPath, err)
+				}
+			} else if string(script) != tt.wantScript {
+				t.Errorf("mei/pre-commit = %q, %v; want %q", script, err, tt.wantScript)
+			}
+
+			// 再度同期しても変わらない
+			syncGitHooks(t, syncer, project, repo)
+			if got := readHook(t, hookPath); got != want {
+				t.Errorf("2回目の同期後のpre-commit = %q, want %q", got, want)
+			}
+
+			// 配布元を削除するとブロックとスクリプトが取り除かれ、手書きの内容だけが残る
+			if err := os.Remove(source); err != nil {
+				t.Fatal(err)
+			}
+			syncGitHooks(t, syncer, project, repo)
+			if got := readHook(t, hookPath); strings.TrimRight(got, "\n") != strings.TrimRight(handWritten, "\n") {
+				t.Errorf("配布元の削除後のpre-commit = %q, want %q", got, handWritten)
+			}
+			if _, err := os.Lstat(scriptPath); !os.IsNotExist(err) {
+				t.Errorf("%s が削除されていません: %v", scriptPath, err)
+			}
+		})
+	}
+}
+
+func TestSyncHooksNonShellHook(t *testing.T) {
+	syncer, project, repo := newHookTestRepo(t)
+	hookPath := filepath.Join(repo.CommonDir, "hooks", "pre-commit")
+	const handWritten = "#!/usr/bin/env node\nconsole.log('lint')\n"
+	if err := os.WriteFile(hookPath, []byte(handWritten), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(os.Getenv("HOME"), ".mei", "git", "hooks", "pre-commit"), "echo mei\n")
+
+	syncGitHooks(t, syncer, project, repo)
+	if got := readHook(t, hookPath); got != handWritten {
+		t.Errorf("シェルスクリプトでないフックが変更されました: %q", got)
+	}
+}
+
+func TestSyncHooksWithIdentityCheck(t *testing.T) {
+	syncer, project, repo := newHookTestRepo(t)
+	hookPath := filepath.Join(repo.CommonDir, "hooks", "pre-commit")
+	source := filepath.Join(os.Getenv("HOME"), ".mei", "git", "hooks", "pre-commit")
+	writeTestFile(t, source, "echo mei\n")
+	identityBlock := config.NewBlockManager("mei:identity-check", identityHookContent, "#").Format()
+	hookBlock := config.NewBlockManager(hookBlockLabel, "(\necho mei\n) || exit $?\n", "#").Format()
+
+	// identityの確認ブロックの後に配布したフックのブロックが追加される
+	if err := installIdentityHook(syncer, repo); err != nil {
+		t.Fatal(err)
+	}
+	syncGitHooks(t, syncer, project, repo)
+	if got, want := readHook(t, hookPath), "#!/bin/sh\n\n"+identityBlock+"\n"+hookBlock; got != want {
+		t.Errorf("pre-commit = %q, want %q", got, want)
+	}
+
+	// それぞれのブロックを更新しても、もう一方のブロックは変わらない
+	if err := installIdentityHook(syncer, repo); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, source, "echo updated\n")
+	syncGitHooks(t, syncer, project, repo)
+	updatedBlock := config.NewBlockManager(hookBlockLabel, "(\necho updated\n) || exit $?\n", "#").Format()
+	if got, want := readHook(t, hookPath), "#!/bin/sh\n\n"+identityBlock+"\n"+updatedBlock; got != want {
+		t.Errorf("更新後のpre-commit = %q, want %q", got, want)
+	}
+
+	// 配布元を削除してもidentityの確認ブロックは残る
+	if err := os.Remove(source); err != nil {
+		t.Fatal(err)
+	}
+	syncGitHooks(t, syncer, project, repo)
+	got := readHook(t, hookPath)
+	if !strings.Contains(got, identityBlock) {
+		t.Errorf("identityの確認ブロックが取り除かれました: %q", got)
+	}
+	if strings.Contains(got, hookBlockLabel+"\n") {
+		t.Errorf("配布したフックのブロックが残っています: %q", got)
+	}
+}
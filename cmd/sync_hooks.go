@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultHookTimeout は同期フックのデフォルトのタイムアウトです
+const defaultHookTimeout = 5 * time.Minute
+
+// hookResult は同期フックの実行結果です
+type hookResult struct {
+	Phase    string        // pre_sync または post_sync
+	Command  string        // 実行したコマンド
+	Output   string        // 標準出力と標準エラー出力
+	Duration time.Duration // 実行時間
+	Err      error         // 失敗した場合のエラー
+}
+
+// hookEnv はフックに渡すmei固有の環境変数を返します
+func hookEnv(project Project) []string {
+	return []string{
+		"MEI_PROJECT_NAME=" + project.Name,
+		"MEI_PROJECT_PATH=" + project.Path,
+		"MEI_GIT_USER=" + project.GitUser,
+	}
+}
+
+// runSyncHooks はプロジェクトディレクトリでフックを順に実行します
+// 失敗したフックがあればそこで中断し、それまでの結果とエラーを返します
+func runSyncHooks(project Project, phase string, commands []string, timeout time.Duration) ([]hookResult, error) {
+	var results []hookResult
+	for _, command := range commands {
+		result := runSyncHook(project, phase, command, timeout)
+		results = append(results, result)
+		if result.Err != nil {
+			return results, fmt.Errorf("%s フック（%s）に失敗しました: %w", phase, command, result.Err)
+		}
+	}
+	return results, nil
+}
+
+// runSyncHook はフックを1つ実行して出力を取得します
+func runSyncHook(project Project, phase, command string, timeout time.Duration) hookResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = project.Path
+	cmd.Env = append(os.Environ(), hookEnv(project)...)
+	// タイムアウト後に子プロセスが出力を開いたままでも待ち続けないようにする
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%sでタイムアウトしました", timeout)
+	}
+
+	return hookResult{
+		Phase:    phase,
+		Command:  command,
+		Output:   string(output),
+		Duration: time.Since(start),
+		Err:      err,
+	}
+}
+
+// printHookResults はフックの実行結果を同期レポートとして表示します
+func printHookResults(results []hookResult) {
+	for _, result := range results {
+		status := "成功"
+		if result.Err != nil {
+			status = fmt.Sprintf("失敗: %v", result.Err)
+		}
+		fmt.Printf("  [%s] %s（%s、%s）\n", result.Phase, result.Command, status, result.Duration.Round(time.Millisecond))
+		for _, line := range strings.Split(strings.TrimRight(result.Output, "\n"), "\n") {
+			if line != "" {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+}
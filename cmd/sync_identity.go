@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mei/internal/config"
+	"mei/internal/gitrepo"
+)
+
+// resolveIdentity はGitUserに対応するidentityを~/.mei/identities.ymlから取得します
+// 定義されていない場合は以前のバージョンと同じ規則で作成したidentityを使うことを表示します
+func resolveIdentity(name string) (config.Identity, error) {
+	identity, ok, err := lookupIdentity(name)
+	if err != nil {
+		return config.Identity{}, err
+	}
+	if !ok {
+		printUndefinedIdentity(name, identity)
+	}
+	return identity, nil
+}
+
+// lookupIdentity はGitUserに対応するidentityを何も表示せずに取得します
+// identities.ymlに定義されていない場合はfalseを返します
+func lookupIdentity(name string) (config.Identity, bool, error) {
+	identities, err := config.LoadIdentities()
+	if err != nil {
+		return config.Identity{}, false, err
+	}
+	return identities.Lookup(name)
+}
+
+// printUndefinedIdentity はidentityが定義されていないため規則で作成したものを使うことを表示します
+func printUndefinedIdentity(name string, identity config.Identity) {
+	fmt.Printf("identity %s が %s に定義されていないため、%s と %s を使用します\n",
+		name, config.IdentitiesPath(), identity.Email, identity.SSHHost)
+}
+
+// syncGitUser はGitUser設定が指定されている場合にGit設定をidentityに合わせて更新します
+func syncGitUser(project Project, syncer *fileSyncer, repo *gitrepo.Repo, identity config.Identity) error {
+	if project.GitUser == "" {
+		return nil
+	}
+
+	// 同じリポジトリのサブプロジェクトに異なるGitユーザーが指定されていれば、後に同期したものが優先されることを警告する
+	projects, err := syncer.registeredProjects()
+	if err != nil {
+		return err
+	}
+	for _, sibling := range projects.siblings(repo) {
+		if sibling.GitUser != "" && sibling.GitUser != project.GitUser {
+			fmt.Printf("警告: %s と %s は同じリポジトリですが異なるGitユーザーが指定されています（%s / %s）\n",
+				project.Name, sibling.Name, project.GitUser, sibling.GitUser)
+		}
+	}
+
+	// ユーザー設定を元に戻せるように.git/configを記録
+	if err := syncer.track(repo.ConfigPath()); err != nil {
+		return err
+	}
+
+	// Git設定を実行
+	settings, err := identityGitConfig(identity)
+	if err != nil {
+		return err
+	}
+	for _, setting := range settings {
+		if err := syncer.runGit(repo.WorkTree, "config", "--local", setting[0], setting[1]); err != nil {
+			return fmt.Errorf("%sの設定に失敗しました: %w", setting[0], err)
+		}
+	}
+
+	// SSH署名の場合は検証用のallowed signersファイルも更新する
+	if syncer.dryRun {
+		if identity.SigningFormat == "ssh" {
+			fmt.Printf("%s allowed signersファイルに %s の公開鍵を追加します\n", dryRunPrefix, identity.Email)
+		}
+	} else if err := updateAllowedSigners(project.GitUser, identity, syncer.track); err != nil {
+		fmt.Printf("警告: %v\n", err)
+	}
+	fmt.Printf("%s のGitユーザー設定を更新しました（%s <%s>）\n", project.Name, identity.Name, identity.Email)
+
+	if project.IdentityHook {
+		if err := installIdentityHook(syncer, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// identityGitConfig はidentityに対応するgit configのキーと値を設定する順に返します
+// ユーザー設定、署名の設定、追加のgit config（キーの順）の順です
+func identityGitConfig(identity config.Identity) ([][2]string, error) {
+	settings := [][2]string{
+		{"user.name", identity.Name},
+		{"user.email", identity.Email},
+	}
+
+	signing, err := signingConfig(identity)
+	if err != nil {
+		return nil, err
+	}
+	settings = append(settings, signing...)
+
+	keys := make([]string, 0, len(identity.GitConfig))
+	for key := range identity.GitConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		settings = append(settings, [2]string{key, identity.GitConfig[key]})
+	}
+	return settings, nil
+}
+
+// identityHookContent はpre-commitフックに書き込むidentityの確認処理です
+// meiが見つからない環境ではコミットを妨げないようにします
+const identityHookContent = `if command -v mei >/dev/null 2>&1; then
+  mei identity check --quiet || exit 1
+fi
+`
+
+// installIdentityHook はコミット前にmei identity checkを実行するブロックを.git/hooks/pre-commitに書き込みます
+func installIdentityHook(syncer *fileSyncer, repo *gitrepo.Repo) error {
+	// linked worktreeでもフックはメインのgitディレクトリのものが使われる
+	hookPath := filepath.Join(repo.CommonDir, "hooks", "pre-commit")
+	if err := syncer.track(hookPath); err != nil {
+		return err
+	}
+	blockManager := config.NewBlockManager("mei:identity-check", identityHookContent, "#")
+	if syncer.dryRun {
+		return syncer.updateBlock(blockManager, hookPath)
+	}
+	if err := syncer.mkdirAll(filepath.Dir(hookPath)); err != nil {
+		return fmt.Errorf("hooksディレクトリの作成に失敗しました: %w", err)
+	}
+
+	// 新しく作成する場合はシェバンを付ける
+	if _, err := os.Stat(hookPath); os.IsNotExist(err) {
+		if err := config.WriteFileAtomic(hookPath, []byte("#!/bin/sh\n"), 0755); err != nil {
+			return fmt.Errorf("pre-commitフックの作成に失敗しました: %w", err)
+		}
+	}
+
+	if err := blockManager.UpdateFile(hookPath); err != nil {
+		return fmt.Errorf("pre-commitフックの更新に失敗しました: %w", err)
+	}
+
+	// 既存のフックに実行権限がなければ付ける
+	info, err := os.Stat(hookPath)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		if err := os.Chmod(hookPath, info.Mode().Perm()|0111); err != nil {
+			return fmt.Errorf("pre-commitフックの権限の変更に失敗しました: %w", err)
+		}
+	}
+	return nil
+}
+
+// originalURLKey は書き換える前のoriginのURLを保存するgit configのキーです
+const originalURLKey = "mei.original-url"
+
+// syncRemote はoriginのホストをidentityのSSHホストエイリアスに書き換えます
+// オーナーやリポジトリ名は既存のURLのものを維持し、originがない場合のみidentityから作成します
+func syncRemote(project Project, syncer *fileSyncer, repo *gitrepo.Repo, identity config.Identity) error {
+	if project.GitUser == "" {
+		return nil
+	}
+
+	// リモートを元に戻せるように.git/configを記録
+	if err := syncer.track(repo.ConfigPath()); err != nil {
+		return err
+	}
+
+	currentURL, err := gitConfigGet(repo.WorkTree, "remote.origin.url")
+	if err != nil {
+		return fmt.Errorf("originの取得に失敗しました: %w", err)
+	}
+	if currentURL == "" {
+		// originがない場合はidentityのオーナーとリポジトリ名（linked worktreeではメインの作業ツリーの名前）で作成
+		remoteURL := identity.RemoteURL(identity.Owner, repo.Name())
+		if err := syncer.runGit(repo.WorkTree, "remote", "add", "origin", remoteURL); err != nil {
+			return fmt.Errorf("リモートの設定に失敗しました: %w", err)
+		}
+		fmt.Printf("%s のoriginを追加しました（%s）\n", project.Name, remoteURL)
+		return nil
+	}
+
+	// ローカルのリポジトリを指すoriginはホストがないため書き換えない
+	if isLocalRemote(currentURL) {
+		return nil
+	}
+
+	remote, err := gitrepo.ParseRemote(currentURL)
+	if err != nil {
+		fmt.Printf("警告: %s のoriginを書き換えませんでした: %v\n", project.Name, err)
+		return nil
+	}
+	if !sameRemoteHost(remote.Host, identity) {
+		fmt.Printf("警告: %s のoriginのホスト %s はidentity %s のホスト %s と異なるため書き換えませんでした\n",
+			project.Name, remote.Host, project.GitUser, identity.Host)
+		return nil
+	}
+
+	remoteURL := remote.SSHURL(identity.SSHHost)
+	if remoteURL == currentURL {
+		return nil
+	}
+
+	// 最初に書き換える前のURLだけを残す
+	original, err := gitConfigGet(repo.WorkTree, originalURLKey)
+	if err != nil {
+		return fmt.Errorf("%sの取得に失敗しました: %w", originalURLKey, err)
+	}
+	if original == "" {
+		if err := syncer.runGit(repo.WorkTree, "config", "--local", originalURLKey, currentURL); err != nil {
+			return fmt.Errorf("%sの保存に失敗しました: %w", originalURLKey, err)
+		}
+	}
+	// remove/addではなくset-urlで書き換え、fetchの設定や追跡ブランチを維持する
+	if err := syncer.runGit(repo.WorkTree, "remote", "set-url", "origin", remoteURL); err != nil {
+		return fmt.Errorf("リモートの設定に失敗しました: %w", err)
+	}
+	fmt.Printf("%s のoriginを書き換えました: %s -> %s\n", project.Name, currentURL, remoteURL)
+	return nil
+}
+
+// sameRemoteHost はリモートのホストがidentityと同じサービスを指しているかを返します
+// 実際のホスト名のほか、github.com-work や alice.github.com のようなSSHホストエイリアスも同じとみなします
+func sameRemoteHost(host string, identity config.Identity) bool {
+	return host == identity.Host ||
+		host == identity.SSHHost ||
+		strings.HasPrefix(host, identity.Host+"-") ||
+		strings.HasSuffix(host, "."+identity.Host)
+}
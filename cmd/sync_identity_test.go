@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"mei/internal/config"
+	"mei/internal/gitrepo"
+)
+
+// testIdentities はテスト用の~/.mei/identities.ymlの内容です
+const testIdentities = `identities:
+  work:
+    name: Alice
+    email: alice@example.com
+    ssh_host: github.com-work
+    owner: acme
+`
+
+// newTestSyncer はHOMEを一時ディレクトリに差し替え、repoだけを含むプロジェクト一覧を使う同期処理を作成します
+// リポジトリの探索はファイルシステムを見ずに、repoの作業ツリー以下のパスをrepoとみなします
+func newTestSyncer(t *testing.T, projects []Project, repo *gitrepo.Repo) *fileSyncer {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeTestFile(t, filepath.Join(home, ".mei", "identities.yml"), testIdentities)
+
+	index := newProjectIndex(projects)
+	index.discover = func(path string) (*gitrepo.Repo, error) {
+		if containsPath(repo.WorkTree, path) {
+			return repo, nil
+		}
+		return nil, gitrepo.ErrNotRepository
+	}
+	syncer := newFileSyncer(config.NewSyncState(), &config.SyncManifest{}, conflictFail, symlinkPreserve)
+	syncer.projects = index
+	return syncer
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetupRepoGitCommands(t *testing.T) {
+	const getOrigin = "config --local --get remote.origin.url"
+	const getOriginal = "config --local --get " + originalURLKey
+	// git config --getはキーが設定されていない場合に終了ステータス1で終了する
+	notFound := &gitrepo.CommandError{ExitCode: 1, Err: errors.New("exit status 1")}
+	broken := &gitrepo.CommandError{ExitCode: 128, Stderr: "fatal: bad config line 1", Err: errors.New("exit status 128")}
+
+	tests := []struct {
+		name    string
+		steps   syncSteps
+		dryRun  bool
+		outputs map[string]string
+		errors  map[string]error
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "git user",
+			steps: syncSteps{stepGitUser: true},
+			want: []string{
+				"git config --local user.name Alice",
+				"git config --local user.email alice@example.com",
+			},
+		},
+		{
+			name:    "remote rewrite",
+			steps:   syncSteps{stepRemote: true},
+			outputs: map[string]string{getOrigin: "https://github.com/acme/app.git"},
+			errors:  map[string]error{getOriginal: notFound},
+			want: []string{
+				"git " + getOrigin,
+				"git " + getOriginal,
+				"git config --local " + originalURLKey + " https://github.com/acme/app.git",
+				"git remote set-url origin git@github.com-work:acme/app.git",
+			},
+		},
+		{
+			name:    "remote already rewritten",
+			steps:   syncSteps{stepRemote: true},
+			outputs: map[string]string{getOrigin: "git@github.com-work:acme/app.git"},
+			want:    []string{"git " + getOrigin},
+		},
+		{
+			name:   "no origin",
+			steps:  syncSteps{stepRemote: true},
+			errors: map[string]error{getOrigin: notFound},
+			want: []string{
+				"git " + getOrigin,
+				"git remote add origin git@github.com-work:acme/app.git",
+			},
+		},
+		{
+			// 設定が読めない場合はoriginがないとみなして追加しない
+			name:    "origin lookup fails",
+			steps:   syncSteps{stepRemote: true},
+			errors:  map[string]error{getOrigin: broken},
+			want:    []string{"git " + getOrigin},
+			wantErr: true,
+		},
+		{
+			name:    "original url lookup fails",
+			steps:   syncSteps{stepRemote: true},
+			outputs: map[string]string{getOrigin: "https://github.com/acme/app.git"},
+			errors:  map[string]error{getOriginal: broken},
+			want: []string{
+				"git " + getOrigin,
+				"git " + getOriginal,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "dry run",
+			steps:   syncSteps{stepGitUser: true, stepRemote: true},
+			dryRun:  true,
+			outputs: map[string]string{getOrigin: "https://github.com/acme/app.git"},
+			errors:  map[string]error{getOriginal: notFound},
+			// 読み取りだけを行い、設定は変更しない
+			want: []string{
+				"git " + getOrigin,
+				"git " + getOriginal,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workTree := filepath.Join(t.TempDir(), "app")
+			repo := &gitrepo.Repo{
+				WorkTree:  workTree,
+				GitDir:    filepath.Join(workTree, ".git"),
+				CommonDir: filepath.Join(workTree, ".git"),
+			}
+			project := Project{Name: "app", Path: workTree, GitUser: "work"}
+			syncer := newTestSyncer(t, []Project{project}, repo)
+			syncer.dryRun = tt.dryRun
+			syncer.beginProject(project)
+
+			fake := &fakeGitRunner{outputs: tt.outputs, errors: tt.errors}
+			useFakeGit(t, fake)
+
+			if err := setupRepo(project, syncer, tt.steps); (err != nil) != tt.wantErr {
+				t.Fatalf("setupRepo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, call := range fake.Calls() {
+				if call.dir != workTree {
+					t.Errorf("%s を %s で実行しました（want %s）", call, call.dir, workTree)
+				}
+				got = append(got, call.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSetupRepoNotRepository(t *testing.T) {
+	workTree := filepath.Join(t.TempDir(), "app")
+	repo := &gitrepo.Repo{WorkTree: workTree, GitDir: filepath.Join(workTree, ".git"), CommonDir: filepath.Join(workTree, ".git")}
+	project := Project{Name: "other", Path: filepath.Join(t.TempDir(), "other"), GitUser: "work"}
+	syncer := newTestSyncer(t, []Project{project}, repo)
+
+	fake := &fakeGitRunner{}
+	useFakeGit(t, fake)
+
+	if err := setupRepo(project, syncer, syncSteps{stepGitUser: true, stepRemote: true}); err != nil {
+		t.Fatalf("setupRepo: %v", err)
+	}
+	if calls := fake.Calls(); len(calls) != 0 {
+		t.Errorf("Gitリポジトリでないプロジェクトでgitを実行しました: %v", calls)
+	}
+}
+
+func TestSetupRepoPrintsUndefinedIdentityOnce(t *testing.T) {
+	workTree := filepath.Join(t.TempDir(), "app")
+	repo := &gitrepo.Repo{WorkTree: workTree, GitDir: filepath.Join(workTree, ".git"), CommonDir: filepath.Join(workTree, ".git")}
+	project := Project{Name: "app", Path: workTree, GitUser: "ghost"}
+	syncer := newTestSyncer(t, []Project{project}, repo)
+	syncer.beginProject(project)
+
+	fake := &fakeGitRunner{outputs: map[string]string{"config --local --get remote.origin.url": "git@github.com:ghost/app.git"}}
+	useFakeGit(t, fake)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = setupRepo(project, syncer, syncSteps{stepGitUser: true, stepRemote: true})
+	})
+	if err != nil {
+		t.Fatalf("setupRepo: %v", err)
+	}
+	if got := strings.Count(output, "identity ghost が"); got != 1 {
+		t.Errorf("未定義のidentityの表示回数 = %d, want 1; output:\n%s", got, output)
+	}
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mei/internal/config"
+	"mei/internal/ignore"
+)
+
+// projectLinks はプロジェクトのシンボリックリンクで同期する宛先を絶対パスで返します
+func projectLinks(project Project) map[string]bool {
+	links := make(map[string]bool)
+	for _, link := range project.Links {
+		links[filepath.Join(project.Path, link)] = true
+	}
+	return links
+}
+
+// symlinkPolicy は同期元にあるシンボリックリンクの扱いを表します
+type symlinkPolicy string
+
+const (
+	symlinkPreserve symlinkPolicy = "preserve" // リンクとして再作成する（デフォルト）
+	symlinkFollow   symlinkPolicy = "follow"   // リンク先の内容をコピーする
+	symlinkSkip     symlinkPolicy = "skip"     // 同期しない
+)
+
+// parseSymlinkPolicy は--symlinksフラグの値を検証します
+func parseSymlinkPolicy(value string) (symlinkPolicy, error) {
+	switch policy := symlinkPolicy(value); policy {
+	case symlinkPreserve, symlinkFollow, symlinkSkip:
+		return policy, nil
+	}
+	return "", fmt.Errorf("不明な--symlinksの値です: %s (preserve, follow, skip のいずれか)", value)
+}
+
+// copySymlink は同期元のシンボリックリンクをsymlinksの設定に従って同期します
+func (s *fileSyncer) copySymlink(src, dst string, filter *ignore.Filter, rel string) error {
+	switch s.symlinks {
+	case symlinkSkip:
+		return nil
+	case symlinkFollow:
+		info, err := os.Stat(src)
+		if err != nil {
+			s.warnf("リンク先が存在しないためスキップしました: %s\n", src)
+			return nil
+		}
+		if info.IsDir() {
+			return s.copyDir(src, dst, filter, rel)
+		}
+		if !info.Mode().IsRegular() {
+			s.warnf("通常のファイルではないためスキップしました: %s\n", src)
+			return nil
+		}
+		if strings.HasSuffix(src, templateSuffix) {
+			return s.renderFile(src, strings.TrimSuffix(dst, templateSuffix))
+		}
+		return s.copyFile(src, dst)
+	}
+
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+
+	// 同期単位の中を指すリンクは、宛先でも対応するパスを指すように相対パスで作成する
+	// 外を指すリンクは絶対パスで作成する
+	resolved := target
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(src), resolved)
+	}
+	resolved = filepath.Clean(resolved)
+	if inside, err := filepath.Rel(s.unitSrc, resolved); err == nil && inside != ".." && !strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+		target, err = filepath.Rel(filepath.Dir(dst), filepath.Join(s.unitDst, inside))
+		if err != nil {
+			return err
+		}
+	} else {
+		target = resolved
+	}
+
+	return s.ensureSymlink(target, dst)
+}
+
+// linkPath は宛先をsrcへのシンボリックリンクにします
+func (s *fileSyncer) linkPath(src, dst string) error {
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+	return s.ensureSymlink(src, dst)
+}
+
+// ensureSymlink は宛先をtargetを指すシンボリックリンクにします
+// 壊れたリンクや別の場所を指すリンクは張り直します
+func (s *fileSyncer) ensureSymlink(target, dst string) error {
+	previous := ""
+	if s.checking {
+		return s.checkSymlink(dst)
+	}
+	if err := s.createDirs(dst); err != nil {
+		return err
+	}
+	if err := s.track(dst); err != nil {
+		return err
+	}
+	info, err := os.Lstat(dst)
+	if s.dryRun {
+		return s.planSymlink(target, dst, err == nil)
+	}
+	switch {
+	case os.IsNotExist(err):
+		// 宛先が存在しない場合はそのまま作成
+	case err != nil:
+		return err
+	case info.Mode()&os.ModeSymlink != 0:
+		current, err := os.Readlink(dst)
+		if err != nil {
+			return err
+		}
+		if current == target {
+			s.stats.Unchanged++
+			return nil
+		}
+		// 別の場所を指すリンクは削除して張り直す
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+		previous = current
+	default:
+		// 実体がある場合は、前回の同期内容から変更がなければ置き換える
+		pristine, err := s.isPristine(dst)
+		if err != nil {
+			return err
+		}
+		if !pristine {
+			switch s.onConflict {
+			case conflictSkip:
+				fmt.Printf("警告: ローカルで変更されているためリンクを作成しませんでした: %s\n", dst)
+				return nil
+			case conflictOverwrite:
+				fmt.Printf("警告: ローカルの変更を破棄してリンクに置き換えました: %s\n", dst)
+			case conflictBackup:
+				backupPath := backupPathFor(dst)
+				if err := s.track(backupPath); err != nil {
+					return err
+				}
+				if err := os.Rename(dst, backupPath); err != nil {
+					return fmt.Errorf("バックアップの作成に失敗しました: %w", err)
+				}
+				fmt.Printf("ローカルの変更をバックアップしました: %s\n", backupPath)
+			default:
+				// マージはリンクに対しては行えないため中断する
+				s.conflicts = append(s.conflicts, dst)
+				return nil
+			}
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		previous = dst
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Symlink(target, dst); err != nil {
+		return fmt.Errorf("シンボリックリンクの作成に失敗しました: %w", err)
+	}
+	if previous != "" {
+		fmt.Printf("シンボリックリンクを修復しました: %s -> %s（以前: %s）\n", dst, target, previous)
+		s.stats.Updated++
+	} else {
+		s.stats.Created++
+	}
+	return nil
+}
+
+// checkSymlink は宛先の実体がローカルで変更されていれば競合として記録します
+func (s *fileSyncer) checkSymlink(dst string) error {
+	info, err := os.Lstat(dst)
+	if os.IsNotExist(err) || (err == nil && info.Mode()&os.ModeSymlink != 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pristine, err := s.isPristine(dst)
+	if err != nil {
+		return err
+	}
+	if !pristine {
+		s.conflicts = append(s.conflicts, dst)
+	}
+	return nil
+}
+
+// planSymlink はdry-runでensureSymlinkが行う変更を表示します
+func (s *fileSyncer) planSymlink(target, dst string, exists bool) error {
+	if current, err := os.Readlink(dst); err == nil && current == target {
+		s.stats.Unchanged++
+		return nil
+	}
+	if exists {
+		fmt.Printf("%s シンボリックリンクに置き換えます: %s -> %s\n", dryRunPrefix, dst, target)
+		s.stats.Updated++
+	} else {
+		fmt.Printf("%s シンボリックリンクを作成します: %s -> %s\n", dryRunPrefix, dst, target)
+		s.stats.Created++
+	}
+	return nil
+}
+
+// isPristine は宛先のすべてのファイルが前回の同期内容のままかを返します
+func (s *fileSyncer) isPristine(dst string) (bool, error) {
+	pristine := true
+	err := filepath.WalkDir(dst, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			pristine = false
+			return filepath.SkipAll
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if recorded, ok := s.state.Files[path]; !ok || recorded != config.HashContent(content) {
+			pristine = false
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return pristine, err
+}
+
+// replaceSymlink は宛先がシンボリックリンクの場合に削除します
+// コピーで同期する場合にリンク先（~/.mei自体など）へ書き込まないようにするためです
+func (s *fileSyncer) replaceSymlink(dst string) error {
+	info, err := os.Lstat(dst)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+	if err := s.track(dst); err != nil {
+		return err
+	}
+	if err := os.Remove(dst); err != nil {
+		return fmt.Errorf("シンボリックリンクの削除に失敗しました: %w", err)
+	}
+	fmt.Printf("シンボリックリンクを実体のコピーに置き換えます: %s\n", dst)
+	return nil
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mei/internal/config"
+)
+
+// signingConfig はidentityの署名設定に対応するgit configのキーと値を順に返します
+// 署名鍵が指定されていない場合は空を返します
+func signingConfig(identity config.Identity) ([][2]string, error) {
+	if identity.SigningKey == "" {
+		return nil, nil
+	}
+
+	settings := [][2]string{
+		{"gpg.format", identity.SigningFormat},
+		{"user.signingkey", signingKeyValue(identity)},
+		{"commit.gpgsign", "true"},
+	}
+	if identity.SigningFormat == "ssh" {
+		path, err := allowedSignersPath()
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, [2]string{"gpg.ssh.allowedSignersFile", path})
+	}
+	return settings, nil
+}
+
+// signingKeyValue はuser.signingkeyに設定する値を返します
+// SSHの鍵はファイルのパスを展開し、鍵そのものが指定されている場合はkey::を付けます
+func signingKeyValue(identity config.Identity) string {
+	key := identity.SigningKey
+	if identity.SigningFormat != "ssh" {
+		return key
+	}
+	if config.IsSSHPublicKey(key) {
+		return "key::" + key
+	}
+	if strings.HasPrefix(key, "key::") {
+		return key
+	}
+	return expandHome(key)
+}
+
+// allowedSignersPath は署名の検証に使うallowed signersファイルのパスを返します
+func allowedSignersPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+	}
+	return filepath.Join(homeDir, ".ssh", "allowed_signers"), nil
+}
+
+// updateAllowedSigners はSSH署名を使うidentityのメールアドレスと公開鍵をallowed signersファイルに書き込みます
+// trackが指定されていれば、書き込む前にスナップショットへ記録します
+func updateAllowedSigners(name string, identity config.Identity, track func(path string) error) error {
+	if identity.SigningKey == "" || identity.SigningFormat != "ssh" {
+		return nil
+	}
+
+	line, err := allowedSignersLine(identity)
+	if err != nil {
+		return err
+	}
+
+	path, err := allowedSignersPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf(".sshディレクトリの作成に失敗しました: %w", err)
+	}
+	if track != nil {
+		if err := track(path); err != nil {
+			return err
+		}
+	}
+
+	blockManager := config.NewBlockManager("mei:"+name, line, "#")
+	if err := blockManager.UpdateFile(path); err != nil {
+		return fmt.Errorf("allowed signersファイルの更新に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// allowedSignersLine はidentityのメールアドレスと公開鍵をallowed signersファイルの1行の形式で返します
+func allowedSignersLine(identity config.Identity) (string, error) {
+	publicKey, err := signingPublicKey(identity.SigningKey)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s namespaces=\"git\" %s\n", identity.Email, publicKey), nil
+}
+
+// signingPublicKey はSSHの署名鍵の指定から「鍵の種類 鍵」の形式の公開鍵を返します
+// 秘密鍵のパスが指定されている場合は対応する.pubファイルを読み込みます
+func signingPublicKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "key::")
+	if !config.IsSSHPublicKey(key) {
+		path := expandHome(key)
+		if !strings.HasSuffix(path, ".pub") {
+			path += ".pub"
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("署名鍵の公開鍵の読み込みに失敗しました: %w", err)
+		}
+		key = string(data)
+	}
+
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return "", fmt.Errorf("SSHの公開鍵の形式が不正です: %s", key)
+	}
+	return fields[0] + " " + fields[1], nil
+}
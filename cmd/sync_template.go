@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// syncTemplateData は同期する.tmplファイルの描画に使うデータです
+type syncTemplateData struct {
+	Name     string            // プロジェクト名
+	Path     string            // プロジェクトのパス
+	GitUser  string            // Gitユーザー名
+	Tags     []string          // プロジェクトのタグ
+	HomeDir  string            // ホームディレクトリ
+	Hostname string            // ホスト名
+	OS       string            // 実行中のOS（runtime.GOOS）
+	Arch     string            // 実行中のアーキテクチャ（runtime.GOARCH）
+	Env      map[string]string // 環境変数
+}
+
+// newSyncTemplateData はプロジェクトと実行環境から描画用データを作成します
+func newSyncTemplateData(project Project) *syncTemplateData {
+	hostname, _ := os.Hostname()
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+
+	return &syncTemplateData{
+		Name:     project.Name,
+		Path:     project.Path,
+		GitUser:  project.GitUser,
+		Tags:     project.Tags,
+		HomeDir:  os.Getenv("HOME"),
+		Hostname: hostname,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Env:      env,
+	}
+}
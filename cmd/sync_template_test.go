@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRenderFile(t *testing.T) {
+	hostname, _ := os.Hostname()
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  string // エラーメッセージに含まれる文字列
+	}{
+		{name: "name", template: "{{.Name}}", want: "app"},
+		{name: "path", template: "{{.Path}}", want: "/work/app"},
+		{name: "git user", template: "{{.GitUser}}", want: "work"},
+		{name: "tags index", template: "{{index .Tags 1}}", want: "cli"},
+		{name: "tags range", template: "{{range .Tags}}[{{.}}]{{end}}", want: "[go][cli]"},
+		{name: "home dir", template: "{{.HomeDir}}", want: "{home}"},
+		{name: "hostname", template: "{{.Hostname}}", want: hostname},
+		{name: "os and arch", template: "{{.OS}}/{{.Arch}}", want: runtime.GOOS + "/" + runtime.GOARCH},
+		{name: "env", template: "{{.Env.MEI_TEST_VALUE}}", want: "from-env"},
+		{name: "conditional", template: `{{if eq .GitUser "work"}}work{{else}}personal{{end}}`, want: "work"},
+		{name: "missing env key", template: "{{.Env.MEI_TEST_MISSING}}", wantErr: "テンプレートの実行に失敗しました"},
+		{name: "unknown field", template: "{{.Owner}}", wantErr: "テンプレートの実行に失敗しました"},
+		{name: "syntax error", template: "{{.Name", wantErr: "テンプレートの解析に失敗しました"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer := newCopySyncer(t)
+			home := os.Getenv("HOME")
+			t.Setenv("MEI_TEST_VALUE", "from-env")
+			syncer.beginProject(Project{Name: "app", Path: "/work/app", GitUser: "work", Tags: []string{"go", "cli"}})
+
+			dir := t.TempDir()
+			src := filepath.Join(dir, "rule.mdc.tmpl")
+			dst := filepath.Join(dir, "rule.mdc")
+			writeTestFile(t, src, tt.template)
+
+			err := syncer.renderFile(src, dst)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("renderFile() error = %v, want %q", err, tt.wantErr)
+				}
+				if _, err := os.Stat(dst); !os.IsNotExist(err) {
+					t.Errorf("描画に失敗したのに %s が作成されています: %v", dst, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renderFile() error = %v", err)
+			}
+			got, err := os.ReadFile(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := strings.ReplaceAll(tt.want, "{home}", home); string(got) != want {
+				t.Errorf("描画結果 = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"mei/internal/config"
+)
+
+const (
+	// defaultWatchInterval は同期元の変更を確認するデフォルトの間隔です
+	defaultWatchInterval = time.Second
+	// watchDebounce は最後の変更からこの時間変更がなければ再同期します
+	watchDebounce = 500 * time.Millisecond
+)
+
+// fileStamp は変更検出に使うファイルの情報です
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+// watchSources は~/.meiの同期元を定期的に確認し、変更があった同期処理だけを再実行します
+// sync.ymlとidentities.ymlの変更も監視して読み込み直し、projects.ymlは再同期のたびに読み込みます
+// allowedは再実行してよい同期処理です（nilの場合はすべて）。Ctrl-Cで終了します
+func watchSources(syncer *fileSyncer, interval time.Duration, allowed syncSteps) error {
+	meiDir, err := meiHomeDir()
+	if err != nil {
+		return err
+	}
+	roots := []string{
+		filepath.Join(meiDir, "cursor"),
+		filepath.Join(meiDir, "github"),
+		filepath.Join(meiDir, "git", "exclude"),
+		filepath.Join(meiDir, "git", "hooks"),
+		filepath.Join(meiDir, "env"),
+		filepath.Join(meiDir, "sync.yml"),
+		config.IdentitiesPath(),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("同期元の変更を監視しています（Ctrl-Cで終了）...")
+	previous := snapshotSources(roots)
+	batch := changeBatch{wait: watchDebounce}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("監視を終了しました")
+			return nil
+		case <-ticker.C:
+		}
+
+		current := snapshotSources(roots)
+		batch.add(diffSnapshots(previous, current), time.Now())
+		previous = current
+
+		changed := batch.take(time.Now())
+		if len(changed) == 0 {
+			continue
+		}
+		if err := resyncChanged(syncer, meiDir, changed, allowed); err != nil {
+			fmt.Println(err)
+		}
+	}
+}
+
+// changeBatch は連続した保存をまとめるため、変更が落ち着くまで変更されたパスをためます
+type changeBatch struct {
+	// wait は最後の変更からこの時間変更がなければ再同期します
+	wait       time.Duration
+	pending    []string
+	lastChange time.Time
+}
+
+// add は変更されたパスを追加します
+func (b *changeBatch) add(changed []string, now time.Time) {
+	if len(changed) == 0 {
+		return
+	}
+	b.pending = append(b.pending, changed...)
+	b.lastChange = now
+}
+
+// take は最後の変更からwait以上経っていれば、たまったパスを返して空にします
+func (b *changeBatch) take(now time.Time) []string {
+	if len(b.pending) == 0 || now.Sub(b.lastChange) < b.wait {
+		return nil
+	}
+	changed := b.pending
+	b.pending = nil
+	return changed
+}
+
+// watchChanges は変更されたパスから決めた再同期の内容です
+type watchChanges struct {
+	steps      syncSteps       // 再実行する同期処理
+	envKeys    map[string]bool // 変更されたenvファイルのキー
+	manifest   bool            // sync.ymlが変更された
+	identities bool            // identities.ymlが変更された
+}
+
+// classifyChanges は変更されたパスに対応する同期処理を返します
+// allowedに含まれない同期処理は実行しません（nilの場合はすべて）
+func classifyChanges(meiDir string, changed []string, allowed syncSteps) watchChanges {
+	changes := watchChanges{steps: syncSteps{}, envKeys: make(map[string]bool)}
+	for _, path := range changed {
+		if path == config.IdentitiesPath() {
+			// identityの変更はGitユーザーとリモートの設定に反映する
+			changes.identities = true
+			changes.steps[stepGitUser] = true
+			changes.steps[stepRemote] = true
+			continue
+		}
+		rel, err := filepath.Rel(meiDir, path)
+		if err != nil {
+			continue
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		switch parts[0] {
+		case "sync.yml":
+			// 同期単位の対象とフックの配布先が変わるため、それらを同期し直す
+			changes.manifest = true
+			changes.steps[stepCursor] = true
+			changes.steps[stepGithub] = true
+			changes.steps[stepHooks] = true
+		case "cursor":
+			changes.steps[stepCursor] = true
+		case "github":
+			changes.steps[stepGithub] = true
+		case "git":
+			if len(parts) > 1 && parts[1] == "hooks" {
+				changes.steps[stepHooks] = true
+			} else {
+				changes.steps[stepExclude] = true
+			}
+		case "env":
+			if len(parts) > 1 {
+				changes.steps[stepEnv] = true
+				changes.envKeys[parts[1]] = true
+			}
+		}
+	}
+	for step := range changes.steps {
+		if !allowed.has(step) {
+			delete(changes.steps, step)
+		}
+	}
+	return changes
+}
+
+// stepsFor はprojectで再実行する同期処理を返します
+// envの変更は該当するキーを使っているプロジェクトにだけ、identityの変更はGitユーザーが指定されたプロジェクトにだけ反映します
+func (c watchChanges) stepsFor(project Project) syncSteps {
+	steps := syncSteps{}
+	for step := range c.steps {
+		switch {
+		case step == stepEnv && !slices.ContainsFunc(project.EnvKeys, func(key string) bool { return c.envKeys[key] }):
+			continue
+		case (step == stepGitUser || step == stepRemote) && project.GitUser == "":
+			continue
+		}
+		steps[step] = true
+	}
+	return steps
+}
+
+// resyncChanged は変更されたパスに対応する同期処理を、影響を受けるプロジェクトにだけ実行します
+func resyncChanged(syncer *fileSyncer, meiDir string, changed []string, allowed syncSteps) error {
+	changes := classifyChanges(meiDir, changed, allowed)
+	if changes.manifest {
+		manifest, err := config.LoadSyncManifest()
+		if err != nil {
+			return err
+		}
+		syncer.manifest = manifest
+		fmt.Println("sync.ymlを読み込み直しました")
+	}
+	if changes.identities {
+		if err := updateSSHConfig(syncer.track); err != nil {
+			fmt.Printf("警告: %v\n", err)
+		}
+		if err := refreshIdentityIncludes(syncer.track); err != nil {
+			fmt.Printf("警告: %v\n", err)
+		}
+	}
+	if len(changes.steps) == 0 {
+		return nil
+	}
+
+	// 監視中に登録されたプロジェクトも対象にするため毎回読み込む
+	projects, err := loadProjects()
+	if err != nil {
+		return err
+	}
+	syncer.projects = newProjectIndex(projects)
+
+	for _, project := range projects {
+		projectSteps := changes.stepsFor(project)
+		if len(projectSteps) == 0 {
+			continue
+		}
+
+		fmt.Printf("プロジェクト %s を再同期中...\n", project.Name)
+		syncer.beginProject(project)
+
+		if projectSteps.has(stepCursor) {
+			if err := syncCursor(project, syncer); err != nil {
+				fmt.Printf("%s の再同期を中断しました: %v\n", project.Name, err)
+				continue
+			}
+			delete(projectSteps, stepCursor)
+		}
+		if len(projectSteps) > 0 {
+			if err := setupRepo(project, syncer, projectSteps); err != nil {
+				fmt.Printf("%s のrepo setup処理に失敗しました: %v\n", project.Name, err)
+				continue
+			}
+		}
+		fmt.Printf("%s の再同期が完了しました（%s）\n", project.Name, syncer.stats)
+	}
+
+	if err := syncer.state.Save(); err != nil {
+		return err
+	}
+	return syncer.saveSnapshot()
+}
+
+// snapshotSources は監視対象のファイル情報を取得します（存在しないパスは無視します）
+func snapshotSources(roots []string) map[string]fileStamp {
+	snapshot := make(map[string]fileStamp)
+	for _, root := range roots {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			snapshot[path] = fileStamp{size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+			return nil
+		})
+	}
+	return snapshot
+}
+
+// diffSnapshots は追加・削除・変更されたパスを返します
+func diffSnapshots(previous, current map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range current {
+		if old, ok := previous[path]; !ok || old != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
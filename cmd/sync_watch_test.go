@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// stepNames はテストで比較しやすいように同期処理を並べ替えて返します
+func stepNames(steps syncSteps) []string {
+	var names []string
+	for step := range steps {
+		names = append(names, string(step))
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestClassifyChanges(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	meiDir := filepath.Join(home, ".mei")
+	path := func(rel string) string { return filepath.Join(meiDir, filepath.FromSlash(rel)) }
+
+	tests := []struct {
+		name           string
+		changed        []string
+		allowed        syncSteps
+		wantSteps      []string
+		wantEnvKeys    []string
+		wantManifest   bool
+		wantIdentities bool
+	}{
+		{
+			name:    "no changes",
+			changed: nil,
+		},
+		{
+			name:      "cursor and github",
+			changed:   []string{path("cursor/rules/a.mdc"), path("github/workflows/ci.yml")},
+			wantSteps: []string{string(stepCursor), string(stepGithub)},
+		},
+		{
+			name:      "hooks and exclude",
+			changed:   []string{path("git/hooks/pre-commit"), path("git/exclude")},
+			wantSteps: []string{string(stepExclude), string(stepHooks)},
+		},
+		{
+			name:        "env keys",
+			changed:     []string{path("env/app/.env"), path("env/api/.env"), path("env")},
+			wantSteps:   []string{string(stepEnv)},
+			wantEnvKeys: []string{"api", "app"},
+		},
+		{
+			name:         "manifest",
+			changed:      []string{path("sync.yml")},
+			wantSteps:    []string{string(stepCursor), string(stepGithub), string(stepHooks)},
+			wantManifest: true,
+		},
+		{
+			name:           "identities",
+			changed:        []string{path("identities.yml")},
+			wantSteps:      []string{string(stepGitUser), string(stepRemote)},
+			wantIdentities: true,
+		},
+		{
+			name:           "identities without remote",
+			changed:        []string{path("identities.yml")},
+			allowed:        syncSteps(nil).without(stepRemote),
+			wantSteps:      []string{string(stepGitUser)},
+			wantIdentities: true,
+		},
+		{
+			name:    "outside mei dir",
+			changed: []string{filepath.Join(home, "other", "file")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := classifyChanges(meiDir, tt.changed, tt.allowed)
+			if got := stepNames(changes.steps); !slices.Equal(got, tt.wantSteps) {
+				t.Errorf("steps = %v, want %v", got, tt.wantSteps)
+			}
+			var envKeys []string
+			for key := range changes.envKeys {
+				envKeys = append(envKeys, key)
+			}
+			slices.Sort(envKeys)
+			if !slices.Equal(envKeys, tt.wantEnvKeys) {
+				t.Errorf("envKeys = %v, want %v", envKeys, tt.wantEnvKeys)
+			}
+			if changes.manifest != tt.wantManifest {
+				t.Errorf("manifest = %v, want %v", changes.manifest, tt.wantManifest)
+			}
+			if changes.identities != tt.wantIdentities {
+				t.Errorf("identities = %v, want %v", changes.identities, tt.wantIdentities)
+			}
+		})
+	}
+}
+
+func TestWatchChangesStepsFor(t *testing.T) {
+	changes := watchChanges{
+		steps:   syncSteps{stepCursor: true, stepEnv: true, stepGitUser: true, stepRemote: true},
+		envKeys: map[string]bool{"app": true},
+	}
+
+	tests := []struct {
+		name    string
+		project Project
+		want    []string
+	}{
+		{
+			name:    "uses changed env key and git user",
+			project: Project{Name: "app", EnvKeys: []string{"app"}, GitUser: "work"},
+			want:    []string{string(stepCursor), string(stepEnv), string(stepGitUser), string(stepRemote)},
+		},
+		{
+			name:    "other env key",
+			project: Project{Name: "api", EnvKeys: []string{"api"}, GitUser: "work"},
+			want:    []string{string(stepCursor), string(stepGitUser), string(stepRemote)},
+		},
+		{
+			name:    "no git user",
+			project: Project{Name: "web"},
+			want:    []string{string(stepCursor)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stepNames(changes.stepsFor(tt.project)); !slices.Equal(got, tt.want) {
+				t.Errorf("stepsFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeBatch(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	batch := changeBatch{wait: 500 * time.Millisecond}
+
+	if got := batch.take(start); got != nil {
+		t.Fatalf("take() on empty batch = %v, want nil", got)
+	}
+
+	batch.add([]string{"a"}, start)
+	if got := batch.take(start.Add(300 * time.Millisecond)); got != nil {
+		t.Fatalf("take() before wait = %v, want nil", got)
+	}
+
+	// 待っている間の変更で待ち時間が延びる
+	batch.add([]string{"b"}, start.Add(400*time.Millisecond))
+	batch.add(nil, start.Add(800*time.Millisecond))
+	if got := batch.take(start.Add(800 * time.Millisecond)); got != nil {
+		t.Fatalf("take() after another change = %v, want nil", got)
+	}
+
+	got := batch.take(start.Add(900 * time.Millisecond))
+	if want := []string{"a", "b"}; !slices.Equal(got, want) {
+		t.Fatalf("take() = %v, want %v", got, want)
+	}
+	if got := batch.take(start.Add(2 * time.Second)); got != nil {
+		t.Fatalf("take() after taking = %v, want nil", got)
+	}
+}
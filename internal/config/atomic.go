@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WriteFileAtomic は同じディレクトリの一時ファイルに書き込んでから置き換えます
+// 途中で中断されても書きかけのファイルが残りません
+// 既存のファイルを置き換える場合はその権限を引き継ぎ、新規作成の場合はpermにumaskを適用します
+// pathがシンボリックリンクの場合はリンクを残したままリンク先を置き換えます
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	mode := os.FileMode(0)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp, err := createTemp(filepath.Dir(path), "."+filepath.Base(path)+".mei-tmp-", perm)
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if mode != 0 {
+		if err := os.Chmod(tmpPath, mode); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// createTemp はpermで一時ファイルを作成します
+// os.CreateTempは常に0600で作成するため、umaskが適用されるように自前で作成します
+func createTemp(dir, prefix string, perm os.FileMode) (*os.File, error) {
+	for {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(rand.Uint64(), 36))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		return f, err
+	}
+}
@@ -0,0 +1,143 @@
+//go:build unix
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// tempFiles はdirに残っている一時ファイルの名前を返します
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".mei-tmp-") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func TestWriteFileAtomicMode(t *testing.T) {
+	// 新規作成の場合にumaskが適用されることを確認するため固定する
+	previous := syscall.Umask(022)
+	t.Cleanup(func() { syscall.Umask(previous) })
+
+	tests := []struct {
+		name     string
+		existing os.FileMode // 0の場合は新規作成
+		perm     os.FileMode
+		want     os.FileMode
+	}{
+		{name: "new file applies umask", perm: 0666, want: 0644},
+		{name: "new executable applies umask", perm: 0777, want: 0755},
+		{name: "existing mode is kept", existing: 0600, perm: 0666, want: 0600},
+		{name: "existing executable is kept", existing: 0750, perm: 0666, want: 0750},
+		{name: "read-only file is replaced", existing: 0444, perm: 0666, want: 0444},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file")
+			if tt.existing != 0 {
+				if err := os.WriteFile(path, []byte("old"), tt.existing); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chmod(path, tt.existing); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := WriteFileAtomic(path, []byte("new"), tt.perm); err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode().Perm(); got != tt.want {
+				t.Errorf("mode = %v; want %v", got, tt.want)
+			}
+			if data, _ := os.ReadFile(path); string(data) != "new" {
+				t.Errorf("content = %q; want %q", data, "new")
+			}
+			if names := tempFiles(t, dir); len(names) > 0 {
+				t.Errorf("一時ファイルが残っています: %v", names)
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	link := filepath.Join(dir, "link")
+	if err := os.WriteFile(target, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("target", link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(link, []byte("new"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	// リンクは残したままリンク先を置き換える
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("リンクが置き換えられました: %v, %v", info, err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("リンク先の内容 = %q; want %q", data, "new")
+	}
+	if names := tempFiles(t, dir); len(names) > 0 {
+		t.Errorf("一時ファイルが残っています: %v", names)
+	}
+}
+
+func TestWriteFileAtomicFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string) string
+	}{
+		{
+			// 一時ファイルは作成できるが、置き換えに失敗する
+			name: "destination is a directory",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "file")
+				if err := os.MkdirAll(filepath.Join(path, "child"), 0755); err != nil {
+					t.Fatal(err)
+				}
+				return path
+			},
+		},
+		{
+			name: "parent does not exist",
+			setup: func(t *testing.T, dir string) string {
+				return filepath.Join(dir, "missing", "file")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := tt.setup(t, dir)
+
+			if err := WriteFileAtomic(path, []byte("new"), 0666); err == nil {
+				t.Fatal("WriteFileAtomic succeeded; want error")
+			}
+			if names := tempFiles(t, dir); len(names) > 0 {
+				t.Errorf("一時ファイルが残っています: %v", names)
+			}
+		})
+	}
+}
@@ -35,6 +35,50 @@ func (b *BlockManager) Format() string {
 		b.CommentPrefix, b.Label)
 }
 
+// pattern はファイル内のブロックに一致する正規表現を返します（コメント記号とラベルをエスケープ）
+func (b *BlockManager) pattern() *regexp.Regexp {
+	escapedPrefix := regexp.QuoteMeta(b.CommentPrefix)
+	escapedLabel := regexp.QuoteMeta(b.Label)
+	return regexp.MustCompile(fmt.Sprintf(`(?s)%s BEGIN:%s\n.*?%s END:%s\n?`,
+		escapedPrefix, escapedLabel,
+		escapedPrefix, escapedLabel))
+}
+
+// Contains は内容にブロックが含まれているかを返します
+func (b *BlockManager) Contains(content string) bool {
+	return b.pattern().MatchString(content)
+}
+
+// Remove は内容からブロックを取り除いた内容を返します
+func (b *BlockManager) Remove(content string) string {
+	return b.pattern().ReplaceAllLiteralString(content, "")
+}
+
+// Apply は既存の内容にブロックを反映した内容を返します
+// ブロックが既にあれば置き換え、なければ末尾に追加します
+func (b *BlockManager) Apply(existingContent string) string {
+	formattedBlock := b.Format()
+	if existingContent == "" {
+		return formattedBlock
+	}
+
+	// 既存のブロックを検索
+	re := b.pattern()
+	if re.MatchString(existingContent) {
+		// 既存のブロックを置換
+		return re.ReplaceAllLiteralString(existingContent, formattedBlock)
+	}
+
+	// ファイル末尾に追加（必要に応じて改行を追加）
+	if !strings.HasSuffix(existingContent, "\n") {
+		existingContent += "\n"
+	}
+	if !strings.HasSuffix(existingContent, "\n\n") {
+		existingContent += "\n"
+	}
+	return existingContent + formattedBlock
+}
+
 // UpdateFile は指定されたファイルの内容を更新します
 func (b *BlockManager) UpdateFile(filepath string) error {
 	// ファイルの読み込み
@@ -43,43 +87,45 @@ func (b *BlockManager) UpdateFile(filepath string) error {
 		return fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
 	}
 
-	formattedBlock := b.Format()
-
 	// ファイルが存在しない場合は新規作成
 	if os.IsNotExist(err) {
-		if err := os.WriteFile(filepath, []byte(formattedBlock), 0644); err != nil {
+		if err := WriteFileAtomic(filepath, []byte(b.Format()), 0644); err != nil {
 			return fmt.Errorf("ファイルの作成に失敗しました: %w", err)
 		}
 		return nil
 	}
 
-	// 既存のブロックを検索（コメント記号をエスケープ）
-	escapedPrefix := regexp.QuoteMeta(b.CommentPrefix)
-	pattern := fmt.Sprintf(`(?s)%s BEGIN:%s.*?%s END:%s\n?`,
-		escapedPrefix, b.Label,
-		escapedPrefix, b.Label)
-	re := regexp.MustCompile(pattern)
-	existingContent := string(content)
-
-	var newContent string
-	if re.MatchString(existingContent) {
-		// 既存のブロックを置換
-		newContent = re.ReplaceAllString(existingContent, formattedBlock)
-	} else {
-		// ファイル末尾に追加（必要に応じて改行を追加）
-		if !strings.HasSuffix(existingContent, "\n") {
-			existingContent += "\n"
-		}
-		if !strings.HasSuffix(existingContent, "\n\n") {
-			existingContent += "\n"
-		}
-		newContent = existingContent + formattedBlock
+	// 内容が変わらない場合は書き込まない（mtimeを保つ）
+	newContent := b.Apply(string(content))
+	if newContent == string(content) {
+		return nil
 	}
 
 	// ファイルに書き戻し
-	if err := os.WriteFile(filepath, []byte(newContent), 0644); err != nil {
+	if err := WriteFileAtomic(filepath, []byte(newContent), 0644); err != nil {
 		return fmt.Errorf("ファイルの書き込みに失敗しました: %w", err)
 	}
 
 	return nil
 }
+
+// RemoveFromFile は指定されたファイルからブロックを取り除きます
+// ファイルやブロックが存在しない場合は何もしません
+func (b *BlockManager) RemoveFromFile(filepath string) error {
+	content, err := os.ReadFile(filepath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ファイルの読み込みに失敗しました: %w", err)
+	}
+
+	newContent := b.Remove(string(content))
+	if newContent == string(content) {
+		return nil
+	}
+	if err := WriteFileAtomic(filepath, []byte(newContent), 0644); err != nil {
+		return fmt.Errorf("ファイルの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
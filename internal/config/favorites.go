@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
 type Favorites struct {
@@ -28,6 +29,30 @@ func (f *Favorites) Add(path string) error {
 	return nil
 }
 
+// Remove は登録されているリポジトリを削除します
+func (f *Favorites) Remove(path string) error {
+	for i, repo := range f.Repositories {
+		if repo == path {
+			f.Repositories = append(f.Repositories[:i], f.Repositories[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("リポジトリは登録されていません: %s", path)
+}
+
+// Prune は有効なGitリポジトリではなくなったものを削除し、削除したパスを返します
+func (f *Favorites) Prune() []string {
+	valid := f.GetValidRepositories()
+	var removed []string
+	for _, repo := range f.Repositories {
+		if !slices.Contains(valid, repo) {
+			removed = append(removed, repo)
+		}
+	}
+	f.Repositories = append(make([]string, 0, len(valid)), valid...)
+	return removed
+}
+
 // GetValidRepositories は有効なGitリポジトリのみを返します
 func (f *Favorites) GetValidRepositories() []string {
 	var validRepos []string
@@ -0,0 +1,365 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SnapshotEntry は同期で変更される前のファイルの状態です
+type SnapshotEntry struct {
+	Project string      `json:"project"`
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Mode    fs.FileMode `json:"mode,omitempty"`
+	Dir     bool        `json:"dir,omitempty"`  // ディレクトリだった場合
+	Link    string      `json:"link,omitempty"` // シンボリックリンクだった場合のリンク先
+	Blob    string      `json:"blob,omitempty"` // 内容を保存したファイル名
+	// SyncHash は変更前にSyncStateに記録されていたハッシュです
+	SyncHash string `json:"sync_hash,omitempty"`
+
+	content []byte
+}
+
+// Snapshot は1回の同期で変更されたファイルの変更前の状態です
+type Snapshot struct {
+	ID        string           `json:"id"`
+	CreatedAt time.Time        `json:"created_at"`
+	Entries   []*SnapshotEntry `json:"entries"`
+
+	// seen は記録済みのプロジェクトとパスの組です
+	// モノレポの.git/configのように複数のプロジェクトが変更するパスは、プロジェクトごとに変更前の状態を記録します
+	seen map[snapshotKey]bool
+}
+
+// snapshotKey はスナップショットに記録したプロジェクトとパスの組です
+type snapshotKey struct {
+	project string
+	path    string
+}
+
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		CreatedAt: time.Now(),
+		seen:      make(map[snapshotKey]bool),
+	}
+}
+
+// Track は変更前のパスの状態を記録します
+// ディレクトリの場合は配下のファイルをすべて記録します。同じプロジェクトの同じパスは最初の状態だけを記録します
+// パスが存在しない場合は、存在しない親ディレクトリも同期で作成するディレクトリとして記録します
+// 復元後に競合と判定されないように、stateに記録されていたハッシュも保存します
+func (s *Snapshot) Track(project, path string, state *SyncState) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if s.seen[snapshotKey{project, path}] {
+		return nil
+	}
+
+	syncHash := state.Files[path]
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		if err := s.TrackDir(project, filepath.Dir(path)); err != nil {
+			return err
+		}
+		s.add(&SnapshotEntry{Project: project, Path: path, SyncHash: syncHash})
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("スナップショットの取得に失敗しました: %w", err)
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return fmt.Errorf("スナップショットの取得に失敗しました: %w", err)
+		}
+		s.add(&SnapshotEntry{Project: project, Path: path, Existed: true, Link: target, SyncHash: syncHash})
+	case info.IsDir():
+		s.seen[snapshotKey{project, path}] = true
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return fmt.Errorf("スナップショットの取得に失敗しました: %w", err)
+		}
+		for _, entry := range entries {
+			if err := s.Track(project, filepath.Join(path, entry.Name()), state); err != nil {
+				return err
+			}
+		}
+		// 逆順に復元するため、ディレクトリ自体は配下より後に記録して先に復元されるようにする
+		s.Entries = append(s.Entries, &SnapshotEntry{Project: project, Path: path, Existed: true, Dir: true})
+	default:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("スナップショットの取得に失敗しました: %w", err)
+		}
+		s.add(&SnapshotEntry{Project: project, Path: path, Existed: true, Mode: info.Mode().Perm(), SyncHash: syncHash, content: content})
+	}
+	return nil
+}
+
+// TrackDir はdirとその親のうち存在しないディレクトリを、同期で作成するディレクトリとして記録します
+// 復元時には空の場合だけ削除します
+func (s *Snapshot) TrackDir(project, dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Lstat(current); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("スナップショットの取得に失敗しました: %w", err)
+		}
+		missing = append(missing, current)
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	// 逆順に復元するため、外側のディレクトリから記録して配下より後に削除されるようにする
+	for i := len(missing) - 1; i >= 0; i-- {
+		if !s.seen[snapshotKey{project, missing[i]}] {
+			s.add(&SnapshotEntry{Project: project, Path: missing[i], Dir: true})
+		}
+	}
+	return nil
+}
+
+func (s *Snapshot) add(entry *SnapshotEntry) {
+	s.seen[snapshotKey{entry.Project, entry.Path}] = true
+	s.Entries = append(s.Entries, entry)
+}
+
+// Save は実際に変更されたパスのみを保存します
+// 変更がなかった場合は何も保存せずにfalseを返します
+func (s *Snapshot) Save() (bool, error) {
+	var changed []*SnapshotEntry
+	for _, entry := range s.Entries {
+		if !entry.unchanged() {
+			changed = append(changed, entry)
+		}
+	}
+	if len(changed) == 0 {
+		return false, nil
+	}
+
+	// 同じ秒に複数回実行された場合も衝突しないIDを割り当てる
+	base := s.CreatedAt.Format("20060102-150405")
+	s.ID = base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(snapshotDir(s.ID)); os.IsNotExist(err) {
+			break
+		}
+		s.ID = base + "-" + strconv.Itoa(i)
+	}
+
+	dir := snapshotDir(s.ID)
+	if err := os.MkdirAll(filepath.Join(dir, "files"), 0755); err != nil {
+		return false, fmt.Errorf("スナップショットディレクトリの作成に失敗しました: %w", err)
+	}
+
+	s.Entries = changed
+	for i, entry := range s.Entries {
+		if entry.content == nil {
+			continue
+		}
+		entry.Blob = strconv.Itoa(i)
+		if err := os.WriteFile(filepath.Join(dir, "files", entry.Blob), entry.content, 0600); err != nil {
+			return false, fmt.Errorf("スナップショットの保存に失敗しました: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("JSONのエンコードに失敗しました: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "snapshot.json"), data, 0644); err != nil {
+		return false, fmt.Errorf("スナップショットの保存に失敗しました: %w", err)
+	}
+	return true, nil
+}
+
+// unchanged は記録時から現在まで状態が変わっていないかを返します
+func (e *SnapshotEntry) unchanged() bool {
+	info, err := os.Lstat(e.Path)
+	if os.IsNotExist(err) {
+		return !e.Existed
+	}
+	if err != nil || !e.Existed {
+		return false
+	}
+	if e.Dir {
+		return info.IsDir()
+	}
+	if e.Link != "" {
+		target, err := os.Readlink(e.Path)
+		return err == nil && target == e.Link
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm() != e.Mode {
+		return false
+	}
+	content, err := os.ReadFile(e.Path)
+	return err == nil && bytes.Equal(content, e.content)
+}
+
+// Projects はスナップショットに含まれるプロジェクト名を返します（~/.ssh/configなどプロジェクトに属さないものは含みません）
+func (s *Snapshot) Projects() []string {
+	seen := make(map[string]bool)
+	var projects []string
+	for _, entry := range s.Entries {
+		if entry.Project != "" && !seen[entry.Project] {
+			seen[entry.Project] = true
+			projects = append(projects, entry.Project)
+		}
+	}
+	return projects
+}
+
+// Restore はスナップショットの状態にファイルとstateの記録を戻し、戻したパスを返します
+// projectが空でない場合はそのプロジェクトのファイルだけを戻します
+func (s *Snapshot) Restore(project string, state *SyncState) ([]string, error) {
+	var restored []string
+	// 後から記録されたものから順に戻す
+	for i := len(s.Entries) - 1; i >= 0; i-- {
+		entry := s.Entries[i]
+		if project != "" && entry.Project != project {
+			continue
+		}
+		ok, err := s.restoreEntry(entry)
+		if err != nil {
+			return restored, fmt.Errorf("%s の復元に失敗しました: %w", entry.Path, err)
+		}
+		if !ok {
+			continue
+		}
+		if !entry.Dir {
+			if entry.SyncHash != "" {
+				state.Files[entry.Path] = entry.SyncHash
+			} else {
+				delete(state.Files, entry.Path)
+			}
+		}
+		restored = append(restored, entry.Path)
+	}
+	return restored, nil
+}
+
+// restoreEntry はエントリの状態にパスを戻します
+// 同期で作成したディレクトリにファイルが追加されていて残した場合はfalseを返します
+func (s *Snapshot) restoreEntry(entry *SnapshotEntry) (bool, error) {
+	info, err := os.Lstat(entry.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	exists := err == nil
+
+	if entry.Dir && !entry.Existed {
+		// 同期で作成したディレクトリは空の場合だけ削除する
+		if !exists || !info.IsDir() {
+			return true, nil
+		}
+		children, err := os.ReadDir(entry.Path)
+		if err != nil {
+			return false, err
+		}
+		if len(children) > 0 {
+			return false, nil
+		}
+		return true, os.Remove(entry.Path)
+	}
+
+	if entry.Dir {
+		// シンボリックリンクなどに置き換えられていればディレクトリに戻す
+		if exists && !info.IsDir() {
+			if err := os.Remove(entry.Path); err != nil {
+				return false, err
+			}
+		}
+		return true, os.MkdirAll(entry.Path, 0755)
+	}
+
+	// 現在の状態を削除する（ディレクトリは同期で作成した配下が復元済みで空の場合のみ削除できる）
+	if exists {
+		if err := os.Remove(entry.Path); err != nil {
+			return false, err
+		}
+	}
+	if !entry.Existed {
+		return true, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0755); err != nil {
+		return false, err
+	}
+	if entry.Link != "" {
+		return true, os.Symlink(entry.Link, entry.Path)
+	}
+	content, err := os.ReadFile(filepath.Join(snapshotDir(s.ID), "files", entry.Blob))
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(entry.Path, content, entry.Mode); err != nil {
+		return false, err
+	}
+	return true, os.Chmod(entry.Path, entry.Mode)
+}
+
+// LoadSnapshot は保存されたスナップショットを読み込みます
+func LoadSnapshot(id string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(snapshotDir(id), "snapshot.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("スナップショットが見つかりません: %s", id)
+		}
+		return nil, fmt.Errorf("スナップショットの読み込みに失敗しました: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("JSONのデコードに失敗しました: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// ListSnapshots は保存されたスナップショットを新しい順に返します
+func ListSnapshots() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(os.Getenv("HOME"), ".local", "state", "mei", "snapshots"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("スナップショットの一覧の取得に失敗しました: %w", err)
+	}
+
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		snapshot, err := LoadSnapshot(entry.Name())
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+func snapshotDir(id string) string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "mei", "snapshots", id)
+}
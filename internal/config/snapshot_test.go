@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSnapshotRestoreCreatedDirs(t *testing.T) {
+	tests := []struct {
+		name         string
+		userFile     string   // 同期後にユーザーが追加するファイル（rootからの相対パス）
+		wantRemain   []string // 復元後に残るパス
+		wantRestored []string // Restoreが返すパス
+	}{
+		{
+			name:         "removes empty created dirs",
+			wantRestored: []string{"a/b/file", "a/b", "a"},
+		},
+		{
+			name:         "keeps dirs with user files",
+			userFile:     "a/notes.txt",
+			wantRemain:   []string{"a", "a/notes.txt"},
+			wantRestored: []string{"a/b/file", "a/b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			root := t.TempDir()
+			file := filepath.Join(root, "a", "b", "file")
+
+			snapshot := NewSnapshot()
+			if err := snapshot.Track("app", file, NewSyncState()); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte("synced"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if saved, err := snapshot.Save(); err != nil || !saved {
+				t.Fatalf("Save() = %v, %v", saved, err)
+			}
+			if tt.userFile != "" {
+				if err := os.WriteFile(filepath.Join(root, tt.userFile), []byte("mine"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			restored, err := snapshot.Restore("app", NewSyncState())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotRestored []string
+			for _, path := range restored {
+				rel, _ := filepath.Rel(root, path)
+				gotRestored = append(gotRestored, filepath.ToSlash(rel))
+			}
+			if !slices.Equal(gotRestored, tt.wantRestored) {
+				t.Errorf("Restore() = %v, want %v", gotRestored, tt.wantRestored)
+			}
+
+			var remain []string
+			filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+				if err == nil && path != root {
+					rel, _ := filepath.Rel(root, path)
+					remain = append(remain, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+			if !slices.Equal(remain, tt.wantRemain) {
+				t.Errorf("復元後のパス = %v, want %v", remain, tt.wantRemain)
+			}
+		})
+	}
+}
+
+func TestSnapshotTrackSharedPathPerProject(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func() string {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	// 2つのプロジェクトが順に同じファイルを変更する
+	write("original")
+	snapshot := NewSnapshot()
+	if err := snapshot.Track("api", path, NewSyncState()); err != nil {
+		t.Fatal(err)
+	}
+	write("api")
+	if err := snapshot.Track("web", path, NewSyncState()); err != nil {
+		t.Fatal(err)
+	}
+	write("web")
+	if _, err := snapshot.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := snapshot.Restore("web", NewSyncState()); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != "api" {
+		t.Errorf("webを戻した後 = %q, want %q", got, "api")
+	}
+	if _, err := snapshot.Restore("api", NewSyncState()); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(); got != "original" {
+		t.Errorf("apiも戻した後 = %q, want %q", got, "original")
+	}
+}
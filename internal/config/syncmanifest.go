@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"mei/internal/ignore"
+)
+
+// SyncUnitNames はsync.ymlのunitsに指定できる同期単位です
+var SyncUnitNames = []string{"cursor", "github"}
+
+// SyncUnit は同期単位（cursor、githubなど）ごとの設定です
+type SyncUnit struct {
+	Include []string `yaml:"include,omitempty"` // 同期するファイルのglob（空の場合はすべて）
+	Exclude []string `yaml:"exclude,omitempty"` // 同期しないファイルのglob
+}
+
+// HookRule は~/.mei/git/hooksのフックを配布するプロジェクトの条件です
+// ProjectsとTagsのどちらかに一致するプロジェクトで有効になります
+type HookRule struct {
+	Projects []string `yaml:"projects,omitempty"` // プロジェクト名
+	Tags     []string `yaml:"tags,omitempty"`     // プロジェクトのタグ
+}
+
+// SyncManifest は~/.mei/sync.ymlの内容です
+type SyncManifest struct {
+	Units map[string]SyncUnit `yaml:"units,omitempty"`
+	Hooks map[string]HookRule `yaml:"hooks,omitempty"`
+}
+
+// HookEnabled はフックがプロジェクトで有効かを返します
+// 条件が指定されていないフックはすべてのプロジェクトで有効です
+func (m *SyncManifest) HookEnabled(hook, project string, tags []string) bool {
+	rule, ok := m.Hooks[hook]
+	if !ok {
+		return true
+	}
+	if slices.Contains(rule.Projects, project) {
+		return true
+	}
+	for _, tag := range tags {
+		if slices.Contains(rule.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSyncManifest は~/.mei/sync.ymlを読み込みます
+// ファイルが存在しない場合は空の設定を返します
+func LoadSyncManifest() (*SyncManifest, error) {
+	data, err := os.ReadFile(filepath.Join(os.Getenv("HOME"), ".mei", "sync.yml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &SyncManifest{}, nil
+		}
+		return nil, fmt.Errorf("sync.ymlの読み込みに失敗しました: %w", err)
+	}
+
+	var manifest SyncManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("sync.ymlの解析に失敗しました: %w", err)
+	}
+	if err := manifest.validate(); err != nil {
+		return nil, fmt.Errorf("sync.ymlが不正です: %w", err)
+	}
+	return &manifest, nil
+}
+
+// validate は同期単位の名前とglobを検証します
+// 無効なglobは同期時に無視されてしまうため、読み込み時にエラーにします
+func (m *SyncManifest) validate() error {
+	for _, name := range slices.Sorted(maps.Keys(m.Units)) {
+		if !slices.Contains(SyncUnitNames, name) {
+			return fmt.Errorf("units.%s は不明な同期単位です（%s のいずれか）", name, strings.Join(SyncUnitNames, "、"))
+		}
+		unit := m.Units[name]
+		for _, pattern := range unit.Include {
+			if !ignore.ValidPattern(pattern) {
+				return fmt.Errorf("units.%s.include のパターン %q は無効です", name, pattern)
+			}
+		}
+		for _, pattern := range unit.Exclude {
+			if !ignore.ValidPattern(pattern) {
+				return fmt.Errorf("units.%s.exclude のパターン %q は無効です", name, pattern)
+			}
+		}
+	}
+	return nil
+}
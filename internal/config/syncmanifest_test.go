@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadSyncManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		content *string // nilの場合はsync.ymlを作成しない
+		want    *SyncManifest
+		wantErr string // エラーメッセージに含まれる文字列
+	}{
+		{
+			name: "no file",
+			want: &SyncManifest{},
+		},
+		{
+			name:    "empty file",
+			content: ptr(""),
+			want:    &SyncManifest{},
+		},
+		{
+			name: "units and hooks",
+			content: ptr(`units:
+  github:
+    include: ["workflows/", "CODEOWNERS"]
+  cursor:
+    exclude: ["**/*.draft.mdc", "!keep.draft.mdc"]
+hooks:
+  pre-push:
+    projects: [app]
+    tags: [work]
+`),
+			want: &SyncManifest{
+				Units: map[string]SyncUnit{
+					"github": {Include: []string{"workflows/", "CODEOWNERS"}},
+					"cursor": {Exclude: []string{"**/*.draft.mdc", "!keep.draft.mdc"}},
+				},
+				Hooks: map[string]HookRule{
+					"pre-push": {Projects: []string{"app"}, Tags: []string{"work"}},
+				},
+			},
+		},
+		{
+			name:    "unit without settings",
+			content: ptr("units:\n  cursor:\n"),
+			want:    &SyncManifest{Units: map[string]SyncUnit{"cursor": {}}},
+		},
+		{
+			name:    "unknown unit",
+			content: ptr("units:\n  vscode:\n    include: [\"*.json\"]\n"),
+			wantErr: "units.vscode は不明な同期単位です",
+		},
+		{
+			name:    "empty include pattern",
+			content: ptr("units:\n  github:\n    include: [\"\"]\n"),
+			wantErr: `units.github.include のパターン "" は無効です`,
+		},
+		{
+			name:    "comment as exclude pattern",
+			content: ptr("units:\n  cursor:\n    exclude: [\"# drafts\"]\n"),
+			wantErr: `units.cursor.exclude のパターン "# drafts" は無効です`,
+		},
+		{
+			name:    "invalid character class",
+			content: ptr("units:\n  cursor:\n    exclude: [\"[z-a].mdc\"]\n"),
+			wantErr: `units.cursor.exclude のパターン "[z-a].mdc" は無効です`,
+		},
+		{
+			name:    "include is not a list",
+			content: ptr("units:\n  github:\n    include: workflows/\n"),
+			wantErr: "sync.ymlの解析に失敗しました",
+		},
+		{
+			name:    "malformed yaml",
+			content: ptr("units: [\n"),
+			wantErr: "sync.ymlの解析に失敗しました",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			if tt.content != nil {
+				path := filepath.Join(home, ".mei", "sync.yml")
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(*tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := LoadSyncManifest()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadSyncManifest() = %+v, %v; want error %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadSyncManifest() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadSyncManifest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHookEnabled(t *testing.T) {
+	manifest := &SyncManifest{Hooks: map[string]HookRule{
+		"pre-push":   {Projects: []string{"app"}, Tags: []string{"work"}},
+		"commit-msg": {},
+	}}
+	tests := []struct {
+		hook    string
+		project string
+		tags    []string
+		want    bool
+	}{
+		{"pre-commit", "other", nil, true},
+		{"pre-push", "app", nil, true},
+		{"pre-push", "other", []string{"oss", "work"}, true},
+		{"pre-push", "other", []string{"oss"}, false},
+		{"commit-msg", "app", []string{"work"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := manifest.HookEnabled(tt.hook, tt.project, tt.tags); got != tt.want {
+			t.Errorf("HookEnabled(%q, %q, %v) = %v, want %v", tt.hook, tt.project, tt.tags, got, tt.want)
+		}
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
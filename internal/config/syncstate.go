@@ -0,0 +1,96 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SyncState はsyncが最後に書き込んだファイルの内容を記録します
+type SyncState struct {
+	// Files は書き込み先の絶対パスから、書き込んだ内容のハッシュへの対応です
+	Files map[string]string `json:"files"`
+}
+
+func NewSyncState() *SyncState {
+	return &SyncState{
+		Files: make(map[string]string),
+	}
+}
+
+// HashContent は内容のハッシュを返します
+func HashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Record は書き込んだ内容を記録します
+// 3-wayマージの共通祖先として使えるように内容そのものもblobとして保存します
+func (s *SyncState) Record(path string, data []byte) error {
+	hash := HashContent(data)
+	blobPath := filepath.Join(syncStateDir(), "blobs", hash)
+	if _, err := os.Stat(blobPath); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(blobPath), 0755); err != nil {
+			return fmt.Errorf("blobディレクトリの作成に失敗しました: %w", err)
+		}
+		if err := os.WriteFile(blobPath, data, 0644); err != nil {
+			return fmt.Errorf("blobの保存に失敗しました: %w", err)
+		}
+	}
+	s.Files[path] = hash
+	return nil
+}
+
+// LoadBlob は記録済みの内容をハッシュから読み込みます
+func (s *SyncState) LoadBlob(hash string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(syncStateDir(), "blobs", hash))
+	if err != nil {
+		return nil, fmt.Errorf("blobの読み込みに失敗しました: %w", err)
+	}
+	return data, nil
+}
+
+func (s *SyncState) Save() error {
+	stateDir := syncStateDir()
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return fmt.Errorf("ステートディレクトリの作成に失敗しました: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSONのエンコードに失敗しました: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(stateDir, "sync-state.json"), data, 0644); err != nil {
+		return fmt.Errorf("同期状態ファイルの保存に失敗しました: %w", err)
+	}
+
+	return nil
+}
+
+func LoadSyncState() (*SyncState, error) {
+	data, err := os.ReadFile(filepath.Join(syncStateDir(), "sync-state.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewSyncState(), nil
+		}
+		return nil, fmt.Errorf("同期状態ファイルの読み込みに失敗しました: %w", err)
+	}
+
+	var state SyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("JSONのデコードに失敗しました: %w", err)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]string)
+	}
+
+	return &state, nil
+}
+
+func syncStateDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "mei")
+}
@@ -0,0 +1,143 @@
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRepository はディレクトリがGitリポジトリではない場合のエラーです
+var ErrNotRepository = errors.New("Gitリポジトリではありません")
+
+// Repo はGitリポジトリの各ディレクトリのパスです
+type Repo struct {
+	WorkTree  string // 作業ツリーのルート（.gitのあるディレクトリ）
+	GitDir    string // このworktreeのgitディレクトリ（.gitファイルの場合はgitdir:が指す先）
+	CommonDir string // configやinfo/exclude、hooksのあるディレクトリ（linked worktreeではメインのgitディレクトリ）
+}
+
+// Resolve はworkTreeの.gitを解決します
+// .gitがファイルの場合（linked worktreeやサブモジュール）はgitdir:をたどり、さらにcommondirをたどります
+func Resolve(workTree string) (*Repo, error) {
+	workTree, err := filepath.Abs(workTree)
+	if err != nil {
+		return nil, err
+	}
+	dotGit := filepath.Join(workTree, ".git")
+
+	info, err := os.Stat(dotGit)
+	if os.IsNotExist(err) {
+		return nil, ErrNotRepository
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gitDir := dotGit
+	if !info.IsDir() {
+		if gitDir, err = readGitFile(dotGit); err != nil {
+			return nil, err
+		}
+	}
+
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = resolvePath(gitDir, strings.TrimSpace(string(data)))
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return &Repo{
+		WorkTree:  workTree,
+		GitDir:    gitDir,
+		CommonDir: commonDir,
+	}, nil
+}
+
+// readGitFile は.gitファイルの「gitdir: <path>」を読み込みます
+func readGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(string(data))
+	target, ok := strings.CutPrefix(line, "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("%s の形式が不正です", path)
+	}
+	gitDir := resolvePath(filepath.Dir(path), strings.TrimSpace(target))
+	if _, err := os.Stat(gitDir); err != nil {
+		return "", fmt.Errorf("%s が指すgitディレクトリが見つかりません: %w", path, err)
+	}
+	return gitDir, nil
+}
+
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// ExcludePath はinfo/excludeのパスを返します（worktree間で共有されます）
+func (r *Repo) ExcludePath() string {
+	return filepath.Join(r.CommonDir, "info", "exclude")
+}
+
+// ConfigPath はリポジトリのconfigのパスを返します（worktree間で共有されます）
+func (r *Repo) ConfigPath() string {
+	return filepath.Join(r.CommonDir, "config")
+}
+
+// IsLinkedWorktree はgit worktree addで作成された作業ツリーかを返します
+func (r *Repo) IsLinkedWorktree() bool {
+	return r.GitDir != r.CommonDir
+}
+
+// Name はリポジトリ名を返します
+// linked worktreeの場合は作業ツリーのディレクトリ名ではなくメインの作業ツリーの名前を返します
+func (r *Repo) Name() string {
+	if r.IsLinkedWorktree() && filepath.Base(r.CommonDir) == ".git" {
+		return filepath.Base(filepath.Dir(r.CommonDir))
+	}
+	return filepath.Base(r.WorkTree)
+}
+
+// Discover はdirから親ディレクトリをたどって、dirを含むリポジトリを探します
+// ceilingより上（ceiling自身を含む）はdirがceilingそのものでない限り探しません
+// ホームディレクトリをdotfilesのリポジトリにしている場合に、無関係なプロジェクトがそのサブプロジェクトとみなされないようにするためです
+func Discover(dir, ceiling string) (*Repo, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if ceiling != "" {
+		if ceiling, err = filepath.Abs(ceiling); err != nil {
+			return nil, err
+		}
+	}
+
+	for current := dir; ; current = filepath.Dir(current) {
+		if current != dir && current == ceiling {
+			return nil, ErrNotRepository
+		}
+		repo, err := Resolve(current)
+		if !errors.Is(err, ErrNotRepository) {
+			return repo, err
+		}
+		if filepath.Dir(current) == current {
+			return nil, ErrNotRepository
+		}
+	}
+}
+
+// RelPath はpathの作業ツリーのルートからの相対パスを返します（ルート自身は「.」）
+func (r *Repo) RelPath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(r.WorkTree, path)
+}
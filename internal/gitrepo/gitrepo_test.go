@@ -0,0 +1,268 @@
+package gitrepo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testLayout はt.TempDir()に作成するリポジトリの構成です
+// dirsは作成するディレクトリ、filesは作成するファイルの内容です（パスはrootからの「/」区切り）
+// ファイルの内容の{root}はrootの絶対パスに置き換えます
+type testLayout struct {
+	dirs  []string
+	files map[string]string
+}
+
+func (l testLayout) create(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range l.dirs {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range l.files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		content = strings.ReplaceAll(content, "{root}", filepath.ToSlash(root))
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// worktreeLayout はmainリポジトリとそのlinked worktree（wt）の構成です
+var worktreeLayout = testLayout{
+	dirs: []string{"main/.git/info", "main/.git/worktrees/wt"},
+	files: map[string]string{
+		"main/.git/worktrees/wt/commondir": "../..\n",
+		"wt/.git":                          "gitdir: {root}/main/.git/worktrees/wt\n",
+		"wt/src/main.go":                   "package main\n",
+	},
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		layout        testLayout
+		workTree      string // rootからの相対パス
+		wantGitDir    string
+		wantCommonDir string
+		wantName      string
+		wantLinked    bool
+		wantErr       bool
+		wantNotRepo   bool
+	}{
+		{
+			name:          "normal repo",
+			layout:        testLayout{dirs: []string{"app/.git"}},
+			workTree:      "app",
+			wantGitDir:    "app/.git",
+			wantCommonDir: "app/.git",
+			wantName:      "app",
+		},
+		{
+			name:          "linked worktree",
+			layout:        worktreeLayout,
+			workTree:      "wt",
+			wantGitDir:    "main/.git/worktrees/wt",
+			wantCommonDir: "main/.git",
+			wantName:      "main",
+			wantLinked:    true,
+		},
+		{
+			name: "linked worktree with relative gitdir",
+			layout: testLayout{
+				dirs: []string{"main/.git/worktrees/wt"},
+				files: map[string]string{
+					"main/.git/worktrees/wt/commondir": "../..\n",
+					"wt/.git":                          "gitdir: ../main/.git/worktrees/wt\n",
+				},
+			},
+			workTree:      "wt",
+			wantGitDir:    "main/.git/worktrees/wt",
+			wantCommonDir: "main/.git",
+			wantName:      "main",
+			wantLinked:    true,
+		},
+		{
+			name: "submodule",
+			layout: testLayout{
+				dirs: []string{"super/.git/modules/lib"},
+				files: map[string]string{
+					"super/lib/.git": "gitdir: ../.git/modules/lib\n",
+				},
+			},
+			workTree:      "super/lib",
+			wantGitDir:    "super/.git/modules/lib",
+			wantCommonDir: "super/.git/modules/lib",
+			wantName:      "lib",
+		},
+		{
+			name: "worktree of a submodule",
+			layout: testLayout{
+				dirs: []string{"super/.git/modules/lib/worktrees/feature"},
+				files: map[string]string{
+					"super/.git/modules/lib/worktrees/feature/commondir": "../..\n",
+					"feature/.git": "gitdir: {root}/super/.git/modules/lib/worktrees/feature\n",
+				},
+			},
+			workTree:      "feature",
+			wantGitDir:    "super/.git/modules/lib/worktrees/feature",
+			wantCommonDir: "super/.git/modules/lib",
+			wantName:      "feature",
+			wantLinked:    true,
+		},
+		{
+			name:        "no .git",
+			layout:      testLayout{dirs: []string{"plain"}},
+			workTree:    "plain",
+			wantNotRepo: true,
+		},
+		{
+			name:     "malformed .git file",
+			layout:   testLayout{files: map[string]string{"app/.git": "not a gitdir line\n"}},
+			workTree: "app",
+			wantErr:  true,
+		},
+		{
+			name:     "gitdir target missing",
+			layout:   testLayout{files: map[string]string{"app/.git": "gitdir: ../missing\n"}},
+			workTree: "app",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := tt.layout.create(t)
+			repo, err := Resolve(filepath.Join(root, filepath.FromSlash(tt.workTree)))
+			switch {
+			case tt.wantNotRepo:
+				if !errors.Is(err, ErrNotRepository) {
+					t.Fatalf("Resolve() error = %v, want ErrNotRepository", err)
+				}
+				return
+			case tt.wantErr:
+				if err == nil || errors.Is(err, ErrNotRepository) {
+					t.Fatalf("Resolve() error = %v, want a resolution error", err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("Resolve() error = %v", err)
+			}
+
+			abs := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+			if repo.WorkTree != abs(tt.workTree) {
+				t.Errorf("WorkTree = %s, want %s", repo.WorkTree, abs(tt.workTree))
+			}
+			if repo.GitDir != abs(tt.wantGitDir) {
+				t.Errorf("GitDir = %s, want %s", repo.GitDir, abs(tt.wantGitDir))
+			}
+			if repo.CommonDir != abs(tt.wantCommonDir) {
+				t.Errorf("CommonDir = %s, want %s", repo.CommonDir, abs(tt.wantCommonDir))
+			}
+			if got := repo.Name(); got != tt.wantName {
+				t.Errorf("Name() = %s, want %s", got, tt.wantName)
+			}
+			if got := repo.IsLinkedWorktree(); got != tt.wantLinked {
+				t.Errorf("IsLinkedWorktree() = %v, want %v", got, tt.wantLinked)
+			}
+			if got, want := repo.ConfigPath(), filepath.Join(abs(tt.wantCommonDir), "config"); got != want {
+				t.Errorf("ConfigPath() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	tests := []struct {
+		name         string
+		layout       testLayout
+		dir          string // rootからの相対パス
+		ceiling      string // rootからの相対パス（空の場合は指定しない）
+		wantWorkTree string
+		wantNotRepo  bool
+	}{
+		{
+			name:         "repo root",
+			layout:       testLayout{dirs: []string{"app/.git"}},
+			dir:          "app",
+			wantWorkTree: "app",
+		},
+		{
+			name:         "sub-project in a monorepo",
+			layout:       testLayout{dirs: []string{"mono/.git", "mono/packages/web"}},
+			dir:          "mono/packages/web",
+			wantWorkTree: "mono",
+		},
+		{
+			name:         "nearest repo wins",
+			layout:       testLayout{dirs: []string{"mono/.git", "mono/vendor/lib/.git", "mono/vendor/lib/src"}},
+			dir:          "mono/vendor/lib/src",
+			wantWorkTree: "mono/vendor/lib",
+		},
+		{
+			name:         "inside a linked worktree",
+			layout:       worktreeLayout,
+			dir:          "wt/src",
+			wantWorkTree: "wt",
+		},
+		{
+			name:        "not a repo",
+			layout:      testLayout{dirs: []string{"plain/sub"}},
+			dir:         "plain/sub",
+			wantNotRepo: true,
+		},
+		{
+			name:        "ceiling stops at a dotfiles repo",
+			layout:      testLayout{dirs: []string{"home/.git", "home/src/app"}},
+			dir:         "home/src/app",
+			ceiling:     "home",
+			wantNotRepo: true,
+		},
+		{
+			name:         "ceiling itself is the project",
+			layout:       testLayout{dirs: []string{"home/.git"}},
+			dir:          "home",
+			ceiling:      "home",
+			wantWorkTree: "home",
+		},
+		{
+			name:         "repo below the ceiling",
+			layout:       testLayout{dirs: []string{"home/.git", "home/src/app/.git", "home/src/app/pkg"}},
+			dir:          "home/src/app/pkg",
+			ceiling:      "home",
+			wantWorkTree: "home/src/app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := tt.layout.create(t)
+			ceiling := ""
+			if tt.ceiling != "" {
+				ceiling = filepath.Join(root, filepath.FromSlash(tt.ceiling))
+			}
+			repo, err := Discover(filepath.Join(root, filepath.FromSlash(tt.dir)), ceiling)
+			if tt.wantNotRepo {
+				if !errors.Is(err, ErrNotRepository) {
+					t.Fatalf("Discover() = %v, %v; want ErrNotRepository", repo, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+			if want := filepath.Join(root, filepath.FromSlash(tt.wantWorkTree)); repo.WorkTree != want {
+				t.Errorf("WorkTree = %s, want %s", repo.WorkTree, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package gitrepo
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Remote はリモートURLを構成要素に分解したものです
+type Remote struct {
+	Scheme string // ssh、https など（scp形式の場合は空）
+	User   string // ユーザー名（git@ の git）
+	Host   string // ホスト名またはSSHのホストエイリアス
+	Port   string // ポート番号（省略時は空）
+	Path   string // 先頭の/を除いたパス（例: owner/repo.git）
+}
+
+// ParseRemote はSSH（scp形式・ssh://）やHTTPSのリモートURLを解析します
+func ParseRemote(rawURL string) (Remote, error) {
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return Remote{}, fmt.Errorf("リモートURLの解析に失敗しました: %w", err)
+		}
+		if u.Host == "" {
+			return Remote{}, fmt.Errorf("リモートURLにホストがありません: %s", rawURL)
+		}
+		remote := Remote{
+			Scheme: u.Scheme,
+			Host:   u.Hostname(),
+			Port:   u.Port(),
+			Path:   strings.TrimPrefix(u.Path, "/"),
+		}
+		if u.User != nil {
+			remote.User = u.User.Username()
+		}
+		return remote, nil
+	}
+
+	// scp形式（[user@]host:path）。最初の:より前に/があればローカルパス
+	hostPart, path, ok := strings.Cut(rawURL, ":")
+	if !ok || hostPart == "" || strings.Contains(hostPart, "/") {
+		return Remote{}, fmt.Errorf("リモートURLの形式が不明です: %s", rawURL)
+	}
+	remote := Remote{Host: hostPart, Path: strings.TrimPrefix(path, "/")}
+	if user, host, ok := strings.Cut(hostPart, "@"); ok {
+		remote.User, remote.Host = user, host
+	}
+	return remote, nil
+}
+
+// SSHURL はホストをhostに置き換えたSSHのリモートURLを返します
+// HTTPSのURLもSSHに変換し、ポートが指定されている場合はssh://形式で返します
+func (r Remote) SSHURL(host string) string {
+	user := r.User
+	if user == "" || (r.Scheme != "" && r.Scheme != "ssh" && r.Scheme != "git+ssh") {
+		user = "git"
+	}
+	if r.Port != "" && (r.Scheme == "ssh" || r.Scheme == "git+ssh") {
+		return fmt.Sprintf("ssh://%s@%s:%s/%s", user, host, r.Port, r.Path)
+	}
+	return fmt.Sprintf("%s@%s:%s", user, host, r.Path)
+}
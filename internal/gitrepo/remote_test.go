@@ -0,0 +1,91 @@
+package gitrepo
+
+import "testing"
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawURL  string
+		want    Remote
+		wantSSH string // SSHURL("github.com-work")の結果
+		wantErr bool
+	}{
+		{
+			name:    "scp style",
+			rawURL:  "git@github.com:acme/app.git",
+			want:    Remote{User: "git", Host: "github.com", Path: "acme/app.git"},
+			wantSSH: "git@github.com-work:acme/app.git",
+		},
+		{
+			name:    "scp style without user",
+			rawURL:  "github.com:acme/app.git",
+			want:    Remote{Host: "github.com", Path: "acme/app.git"},
+			wantSSH: "git@github.com-work:acme/app.git",
+		},
+		{
+			name:    "ssh with port",
+			rawURL:  "ssh://git@github.com:2222/acme/app.git",
+			want:    Remote{Scheme: "ssh", User: "git", Host: "github.com", Port: "2222", Path: "acme/app.git"},
+			wantSSH: "ssh://git@github.com-work:2222/acme/app.git",
+		},
+		{
+			name:    "ssh without port",
+			rawURL:  "ssh://deploy@github.com/acme/app.git",
+			want:    Remote{Scheme: "ssh", User: "deploy", Host: "github.com", Path: "acme/app.git"},
+			wantSSH: "deploy@github.com-work:acme/app.git",
+		},
+		{
+			name:    "https",
+			rawURL:  "https://github.com/acme/app.git",
+			want:    Remote{Scheme: "https", Host: "github.com", Path: "acme/app.git"},
+			wantSSH: "git@github.com-work:acme/app.git",
+		},
+		{
+			// HTTPSのポートはSSHでは使えないため引き継がない
+			name:    "https with port",
+			rawURL:  "https://github.com:8443/acme/app.git",
+			want:    Remote{Scheme: "https", Host: "github.com", Port: "8443", Path: "acme/app.git"},
+			wantSSH: "git@github.com-work:acme/app.git",
+		},
+		{
+			name:    "https without .git",
+			rawURL:  "https://github.com/acme/app",
+			want:    Remote{Scheme: "https", Host: "github.com", Path: "acme/app"},
+			wantSSH: "git@github.com-work:acme/app",
+		},
+		{
+			// HTTPSのユーザー名（トークンなど）はSSHのユーザーにしない
+			name:    "https with user",
+			rawURL:  "https://alice@github.com/acme/app.git",
+			want:    Remote{Scheme: "https", User: "alice", Host: "github.com", Path: "acme/app.git"},
+			wantSSH: "git@github.com-work:acme/app.git",
+		},
+		{name: "empty", rawURL: "", wantErr: true},
+		{name: "no colon", rawURL: "github.com", wantErr: true},
+		{name: "local absolute path", rawURL: "/srv/git/app.git", wantErr: true},
+		{name: "local relative path with colon", rawURL: "./repos/a:b.git", wantErr: true},
+		{name: "scheme without host", rawURL: "file:///srv/git/app.git", wantErr: true},
+		{name: "malformed url", rawURL: "https://github.com:port/acme/app.git", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRemote(tt.rawURL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRemote(%q) = %+v, want error", tt.rawURL, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRemote(%q) error = %v", tt.rawURL, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRemote(%q) = %+v, want %+v", tt.rawURL, got, tt.want)
+			}
+			if ssh := got.SSHURL("github.com-work"); ssh != tt.wantSSH {
+				t.Errorf("SSHURL() = %s, want %s", ssh, tt.wantSSH)
+			}
+		})
+	}
+}
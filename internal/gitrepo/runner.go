@@ -0,0 +1,67 @@
+package gitrepo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Runner はgitコマンドを実行します
+// テストではgitを実行せずにコマンドを記録する実装に差し替えます
+type Runner interface {
+	// Run はdirでgitコマンドを実行し、標準出力を返します
+	Run(ctx context.Context, dir string, args ...string) (string, error)
+}
+
+// CommandError はgitコマンドが失敗した場合のエラーです
+type CommandError struct {
+	Args     []string // gitに渡した引数
+	Stderr   string   // 標準エラー出力（前後の空白を除いたもの）
+	ExitCode int      // gitの終了ステータス（起動できなかった場合やタイムアウトした場合は-1）
+	Err      error    // 終了ステータスやタイムアウトなどの元のエラー
+}
+
+func (e *CommandError) Error() string {
+	command := "git " + strings.Join(e.Args, " ")
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return fmt.Sprintf("%s がタイムアウトしました", command)
+	}
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s: %s", command, e.Stderr)
+	}
+	return fmt.Sprintf("%s: %v", command, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// ExecRunner はgitを実際に実行するRunnerです
+type ExecRunner struct{}
+
+// Run はdirでgitコマンドを実行し、失敗した場合は標準エラー出力を含むCommandErrorを返します
+// ctxがキャンセルされるかタイムアウトするとgitを終了させます
+func (ExecRunner) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		exitCode := -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+			exitCode = -1
+		}
+		return stdout.String(), &CommandError{Args: args, Stderr: strings.TrimSpace(stderr.String()), ExitCode: exitCode, Err: err}
+	}
+	return stdout.String(), nil
+}
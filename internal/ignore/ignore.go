@@ -0,0 +1,184 @@
+package ignore
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// FileName は同期元ディレクトリに置く除外設定ファイルの名前です
+const FileName = ".meiignore"
+
+// DefaultPatterns は常に除外するパターンです
+var DefaultPatterns = []string{
+	FileName,
+	".DS_Store",
+	"*.swp",
+	"*.swo",
+	"*~",
+}
+
+// rule はgitignore形式の1行分のパターンです
+type rule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Filter は同期するファイルを選択します
+// 除外パターンはgitignoreと同じく後から追加したものが優先され、「!」で再び含めることができます
+type Filter struct {
+	exclude []rule
+	include []rule
+}
+
+// New は除外パターンと対象パターンからFilterを作成します
+// includeが空でない場合は、いずれかに一致するファイルだけを対象にします
+func New(exclude, include []string) *Filter {
+	f := &Filter{}
+	for _, pattern := range exclude {
+		if r, ok := parseRule(pattern, ""); ok {
+			f.exclude = append(f.exclude, r)
+		}
+	}
+	for _, pattern := range include {
+		if r, ok := parseRule(pattern, ""); ok {
+			f.include = append(f.include, r)
+		}
+	}
+	return f
+}
+
+// WithIgnoreFile はdir（同期元のルートからの相対パス）にある.meiignoreの内容を追加したFilterを返します
+// 追加したパターンはdir配下にだけ適用されます
+func (f *Filter) WithIgnoreFile(dir, content string) *Filter {
+	next := &Filter{
+		exclude: append([]rule(nil), f.exclude...),
+		include: f.include,
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if r, ok := parseRule(line, dir); ok {
+			next.exclude = append(next.exclude, r)
+		}
+	}
+	return next
+}
+
+// Excluded はrel（同期元のルートからの相対パス）が除外されるかを返します
+func (f *Filter) Excluded(rel string, isDir bool) bool {
+	rel = path.Clean(rel)
+	excluded := false
+	for _, r := range f.exclude {
+		if r.match(rel, isDir) {
+			excluded = !r.negate
+		}
+	}
+	if excluded || isDir || len(f.include) == 0 {
+		return excluded
+	}
+
+	// 対象パターンはファイル自身か親ディレクトリのいずれかに一致すればよい
+	// ファイルに近い階層で一致したパターン（「!」による否定を含む）を優先する
+	for p, dir := rel, false; p != "." && p != "/"; p, dir = path.Dir(p), true {
+		matched, included := false, false
+		for _, r := range f.include {
+			if r.match(p, dir) {
+				matched, included = true, !r.negate
+			}
+		}
+		if matched {
+			return !included
+		}
+	}
+	return true
+}
+
+func (r rule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	return r.re.MatchString(rel)
+}
+
+// ValidPattern はglobがパターンとして使えるかを返します（空行やコメントは使えません）
+func ValidPattern(pattern string) bool {
+	_, ok := parseRule(pattern, "")
+	return ok
+}
+
+// parseRule はgitignore形式の1行をbase配下に適用されるルールに変換します
+func parseRule(line, base string) (rule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	var r rule
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	// 途中にスラッシュを含むパターンは.meiignoreのあるディレクトリからの相対パスとして扱う
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	prefix := ""
+	if base != "" && base != "." {
+		prefix = regexp.QuoteMeta(path.Clean(base)) + "/"
+	}
+	if !anchored {
+		prefix += "(?:.*/)?"
+	}
+
+	re, err := regexp.Compile("^" + prefix + globToRegexp(line) + "$")
+	if err != nil {
+		return rule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+// globToRegexp は*、?、**、[...]を含むglobを正規表現に変換します
+func globToRegexp(glob string) string {
+	var buf strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			buf.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			buf.WriteString(".*")
+			i++
+		case c == '*':
+			buf.WriteString("[^/]*")
+		case c == '?':
+			buf.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				buf.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			buf.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return buf.String()
+}
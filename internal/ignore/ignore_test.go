@@ -0,0 +1,121 @@
+package ignore
+
+import "testing"
+
+// ignoreFile は.meiignoreの置かれたディレクトリと内容です
+type ignoreFile struct {
+	dir, content string
+}
+
+func TestExcluded(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude []string
+		include []string
+		files   []ignoreFile
+		rel     string
+		isDir   bool
+		want    bool
+	}{
+		// パターンの位置
+		{name: "unanchored matches at any depth", exclude: []string{"*.log"}, rel: "a/b/debug.log", want: true},
+		{name: "unanchored name matches directory", exclude: []string{"tmp"}, rel: "a/tmp", isDir: true, want: true},
+		{name: "leading slash anchors to root", exclude: []string{"/build"}, rel: "build", isDir: true, want: true},
+		{name: "leading slash does not match deeper", exclude: []string{"/build"}, rel: "src/build", isDir: true, want: false},
+		{name: "middle slash anchors to root", exclude: []string{"docs/*.md"}, rel: "docs/a.md", want: true},
+		{name: "middle slash does not match deeper", exclude: []string{"docs/*.md"}, rel: "x/docs/a.md", want: false},
+		{name: "star does not cross slash", exclude: []string{"docs/*.md"}, rel: "docs/sub/a.md", want: false},
+
+		// **
+		{name: "leading double star matches root", exclude: []string{"**/cache"}, rel: "cache", isDir: true, want: true},
+		{name: "leading double star matches deeper", exclude: []string{"**/cache"}, rel: "a/b/cache", isDir: true, want: true},
+		{name: "middle double star matches zero dirs", exclude: []string{"a/**/b.txt"}, rel: "a/b.txt", want: true},
+		{name: "middle double star matches many dirs", exclude: []string{"a/**/b.txt"}, rel: "a/x/y/b.txt", want: true},
+		{name: "trailing double star matches contents", exclude: []string{"vendor/**"}, rel: "vendor/x/y.go", want: true},
+		{name: "trailing double star does not match the dir itself", exclude: []string{"vendor/**"}, rel: "vendor", isDir: true, want: false},
+
+		// 否定
+		{name: "negation overrides earlier exclude", exclude: []string{"*.md", "!README.md"}, rel: "README.md", want: false},
+		{name: "negation keeps other excludes", exclude: []string{"*.md", "!README.md"}, rel: "CHANGELOG.md", want: true},
+		{name: "later exclude overrides negation", exclude: []string{"!README.md", "*.md"}, rel: "README.md", want: true},
+		{name: "escaped exclamation is literal", exclude: []string{`\!important`}, rel: "!important", want: true},
+
+		// ディレクトリだけのルール
+		{name: "dir-only matches directory", exclude: []string{"build/"}, rel: "build", isDir: true, want: true},
+		{name: "dir-only skips file", exclude: []string{"build/"}, rel: "build", want: false},
+		{name: "dir-only matches nested directory", exclude: []string{"build/"}, rel: "a/build", isDir: true, want: true},
+
+		// 文字クラス
+		{name: "class matches", exclude: []string{"file[0-9].txt"}, rel: "file3.txt", want: true},
+		{name: "class does not match", exclude: []string{"file[0-9].txt"}, rel: "filex.txt", want: false},
+		{name: "negated class matches", exclude: []string{"file[!0-9].txt"}, rel: "filex.txt", want: true},
+		{name: "negated class does not match", exclude: []string{"file[!0-9].txt"}, rel: "file3.txt", want: false},
+		{name: "question mark matches one char", exclude: []string{"?.txt"}, rel: "a.txt", want: true},
+		{name: "question mark does not match slash", exclude: []string{"a?b"}, rel: "a/b", want: false},
+
+		// .meiignoreの範囲
+		{name: "nested ignore applies below its dir", files: []ignoreFile{{"rules", "*.bak"}}, rel: "rules/x/a.bak", want: true},
+		{name: "nested ignore does not apply elsewhere", files: []ignoreFile{{"rules", "*.bak"}}, rel: "other/a.bak", want: false},
+		{name: "nested anchored pattern is relative to its dir", files: []ignoreFile{{"rules", "/draft"}}, rel: "rules/draft", isDir: true, want: true},
+		{name: "nested anchored pattern does not match deeper", files: []ignoreFile{{"rules", "/draft"}}, rel: "rules/x/draft", isDir: true, want: false},
+		{name: "nested negation overrides parent exclude", exclude: []string{"*.md"}, files: []ignoreFile{{"rules", "!keep.md"}}, rel: "rules/keep.md", want: false},
+		{name: "nested negation does not leak to siblings", exclude: []string{"*.md"}, files: []ignoreFile{{"rules", "!keep.md"}}, rel: "keep.md", want: true},
+		{name: "comments and blank lines are ignored", files: []ignoreFile{{".", "# *.md\n\n*.tmp\n"}}, rel: "a.md", want: false},
+		{name: "CRLF ignore file", files: []ignoreFile{{".", "*.tmp\r\n"}}, rel: "a.tmp", want: true},
+
+		// 対象パターン
+		{name: "include matches file", include: []string{"*.mdc"}, rel: "rules/a.mdc", want: false},
+		{name: "include excludes other files", include: []string{"*.mdc"}, rel: "rules/a.txt", want: true},
+		{name: "include by parent directory", include: []string{"rules"}, rel: "rules/sub/a.txt", want: false},
+		{name: "include by anchored parent", include: []string{"/rules/"}, rel: "rules/a.txt", want: false},
+		{name: "include does not filter directories", include: []string{"*.mdc"}, rel: "rules", isDir: true, want: false},
+		{name: "exclude wins over include", exclude: []string{"draft.mdc"}, include: []string{"*.mdc"}, rel: "draft.mdc", want: true},
+		{name: "negated include", include: []string{"rules", "!rules/private"}, rel: "rules/private/a.txt", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := New(tt.exclude, tt.include)
+			for _, file := range tt.files {
+				filter = filter.WithIgnoreFile(file.dir, file.content)
+			}
+			if got := filter.Excluded(tt.rel, tt.isDir); got != tt.want {
+				t.Errorf("Excluded(%q, %v) = %v; want %v", tt.rel, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultPatterns(t *testing.T) {
+	filter := New(DefaultPatterns, nil)
+	for _, rel := range []string{".meiignore", "a/.DS_Store", "a/b.swp", "notes.txt~"} {
+		if !filter.Excluded(rel, false) {
+			t.Errorf("Excluded(%q) = false; want true", rel)
+		}
+	}
+	if filter.Excluded("rules/a.mdc", false) {
+		t.Error("Excluded(rules/a.mdc) = true; want false")
+	}
+}
+
+func TestValidPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"*.mdc", true},
+		{"workflows/", true},
+		{"!keep.mdc", true},
+		{`\#notes`, true},
+		{"[a-z].mdc", true},
+		{"", false},
+		{"/", false},
+		{"# comment", false},
+		{"[z-a].mdc", false},
+	}
+	for _, tt := range tests {
+		if got := ValidPattern(tt.pattern); got != tt.want {
+			t.Errorf("ValidPattern(%q) = %v; want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package merge
+
+import (
+	"strings"
+)
+
+// Merge はbaseを共通祖先としてoursとtheirsを行単位で3-wayマージします
+// 両側で同じ箇所が異なる内容に変更されている場合は競合マーカーを挿入し、cleanにfalseを返します
+// 改行コードはoursに合わせ（oursが空の場合はtheirs、baseの順）、3者ともその改行コードに揃えてからマージします
+// 競合マーカーにも同じ改行コードを使います
+func Merge(base, ours, theirs string) (merged string, clean bool) {
+	eol := lineEnding(ours, theirs, base)
+	base, ours, theirs = normalize(base, eol), normalize(ours, eol), normalize(theirs, eol)
+
+	// 最終行の改行の有無で最後の行が一致しなくならないように改行を補ってからマージし、
+	// 改行の有無そのものは別に3-wayで決める
+	finalNewline := hasFinalNewline(ours)
+	if finalNewline == hasFinalNewline(base) {
+		finalNewline = hasFinalNewline(theirs)
+	}
+
+	merged, clean = mergeLines(
+		splitLines(terminate(base, eol)),
+		splitLines(terminate(ours, eol)),
+		splitLines(terminate(theirs, eol)),
+		eol,
+	)
+	if clean && !finalNewline {
+		merged = strings.TrimSuffix(merged, eol)
+	}
+	return merged, clean
+}
+
+// mergeLines は行に分割した3つの内容をマージします
+func mergeLines(baseLines, oursLines, theirsLines []string, eol string) (string, bool) {
+	matchOurs := matchLines(baseLines, oursLines)
+	matchTheirs := matchLines(baseLines, theirsLines)
+
+	var buf strings.Builder
+	clean := true
+	i, a, b := 0, 0, 0
+	for {
+		// 3者すべてで一致する次の行（安定行）を探す
+		j := i
+		for j < len(baseLines) && (matchOurs[j] < 0 || matchTheirs[j] < 0) {
+			j++
+		}
+
+		endOurs, endTheirs := len(oursLines), len(theirsLines)
+		if j < len(baseLines) {
+			endOurs, endTheirs = matchOurs[j], matchTheirs[j]
+		}
+
+		if !resolveChunk(&buf, baseLines[i:j], oursLines[a:endOurs], theirsLines[b:endTheirs], eol) {
+			clean = false
+		}
+
+		if j >= len(baseLines) {
+			break
+		}
+
+		buf.WriteString(baseLines[j])
+		i, a, b = j+1, endOurs+1, endTheirs+1
+	}
+
+	return buf.String(), clean
+}
+
+// resolveChunk は安定行に挟まれた変更箇所を解決して書き出します
+func resolveChunk(buf *strings.Builder, base, ours, theirs []string, eol string) bool {
+	switch {
+	case equalLines(ours, theirs):
+		writeLines(buf, ours)
+	case equalLines(base, ours):
+		writeLines(buf, theirs)
+	case equalLines(base, theirs):
+		writeLines(buf, ours)
+	default:
+		buf.WriteString("<<<<<<< local" + eol)
+		writeLines(buf, ours)
+		buf.WriteString("=======" + eol)
+		writeLines(buf, theirs)
+		buf.WriteString(">>>>>>> mei" + eol)
+		return false
+	}
+	return true
+}
+
+// matchLines はLCSによりbaseの各行に対応するotherの行番号を返します（対応なしは-1）
+func matchLines(base, other []string) []int {
+	n, m := len(base), len(other)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if base[i] == other[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	match := make([]int, n)
+	for i := range match {
+		match[i] = -1
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case base[i] == other[j]:
+			match[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match
+}
+
+// lineEnding は最初に改行を含む内容の改行コード（\r\nまたは\n）を返します
+func lineEnding(contents ...string) string {
+	for _, content := range contents {
+		if i := strings.Index(content, "\n"); i >= 0 {
+			if i > 0 && content[i-1] == '\r' {
+				return "\r\n"
+			}
+			return "\n"
+		}
+	}
+	return "\n"
+}
+
+// normalize は改行コードをすべてeolに揃えます
+func normalize(s, eol string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if eol != "\n" {
+		s = strings.ReplaceAll(s, "\n", eol)
+	}
+	return s
+}
+
+// hasFinalNewline は内容が改行で終わっているか（空の場合も含む）を返します
+func hasFinalNewline(s string) bool {
+	return s == "" || strings.HasSuffix(s, "\n")
+}
+
+// terminate は最終行に改行がない場合にeolを補います
+func terminate(s, eol string) string {
+	if hasFinalNewline(s) {
+		return s
+	}
+	return s + eol
+}
+
+// splitLines は改行を保持したまま行に分割します
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func equalLines(x, y []string) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(buf *strings.Builder, lines []string) {
+	for _, line := range lines {
+		buf.WriteString(line)
+	}
+}
@@ -0,0 +1,173 @@
+package merge
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		wantClean          bool
+	}{
+		{
+			name:      "non-overlapping edits",
+			base:      "a\nb\nc\nd\ne\n",
+			ours:      "A\nb\nc\nd\ne\n",
+			theirs:    "a\nb\nc\nd\nE\n",
+			want:      "A\nb\nc\nd\nE\n",
+			wantClean: true,
+		},
+		{
+			name:      "insert and delete in different places",
+			base:      "a\nb\nc\nd\n",
+			ours:      "a\nx\nb\nc\nd\n",
+			theirs:    "a\nb\nc\n",
+			want:      "a\nx\nb\nc\n",
+			wantClean: true,
+		},
+		{
+			name:   "overlapping edits",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB1\nc\n",
+			theirs: "a\nB2\nc\n",
+			want:   "a\n<<<<<<< local\nB1\n=======\nB2\n>>>>>>> mei\nc\n",
+		},
+		{
+			name:   "edit against delete",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nc\n",
+			want:   "a\n<<<<<<< local\nB\n=======\n>>>>>>> mei\nc\n",
+		},
+		{
+			name:      "identical edits",
+			base:      "a\nb\nc\n",
+			ours:      "a\nX\nc\n",
+			theirs:    "a\nX\nc\n",
+			want:      "a\nX\nc\n",
+			wantClean: true,
+		},
+		{
+			name:      "only theirs changed",
+			base:      "a\nb\n",
+			ours:      "a\nb\n",
+			theirs:    "a\nb\nc\n",
+			want:      "a\nb\nc\n",
+			wantClean: true,
+		},
+		{
+			name:      "empty base with one side added",
+			base:      "",
+			ours:      "",
+			theirs:    "x\ny\n",
+			want:      "x\ny\n",
+			wantClean: true,
+		},
+		{
+			name:      "empty base with identical additions",
+			base:      "",
+			ours:      "x\n",
+			theirs:    "x\n",
+			want:      "x\n",
+			wantClean: true,
+		},
+		{
+			name:   "empty base with different additions",
+			base:   "",
+			ours:   "x\n",
+			theirs: "y\n",
+			want:   "<<<<<<< local\nx\n=======\ny\n>>>>>>> mei\n",
+		},
+		{
+			name:      "all empty",
+			wantClean: true,
+		},
+		{
+			name:      "missing trailing newline everywhere",
+			base:      "a\nb",
+			ours:      "A\nb",
+			theirs:    "a\nb\nc",
+			want:      "A\nb\nc",
+			wantClean: true,
+		},
+		{
+			name:      "theirs adds trailing newline",
+			base:      "a\nb",
+			ours:      "A\nb",
+			theirs:    "a\nb\n",
+			want:      "A\nb\n",
+			wantClean: true,
+		},
+		{
+			name:      "ours removes trailing newline",
+			base:      "a\nb\n",
+			ours:      "a\nb",
+			theirs:    "A\nb\n",
+			want:      "A\nb",
+			wantClean: true,
+		},
+		{
+			name:   "conflict on last line without newline",
+			base:   "a\nb",
+			ours:   "a\nB1",
+			theirs: "a\nB2",
+			want:   "a\n<<<<<<< local\nB1\n=======\nB2\n>>>>>>> mei\n",
+		},
+		{
+			name:      "CRLF non-overlapping edits",
+			base:      "a\r\nb\r\nc\r\n",
+			ours:      "A\r\nb\r\nc\r\n",
+			theirs:    "a\r\nb\r\nC\r\n",
+			want:      "A\r\nb\r\nC\r\n",
+			wantClean: true,
+		},
+		{
+			name:      "CRLF missing trailing newline",
+			base:      "a\r\nb",
+			ours:      "A\r\nb",
+			theirs:    "a\r\nb\r\nc",
+			want:      "A\r\nb\r\nc",
+			wantClean: true,
+		},
+		{
+			name:   "CRLF conflict markers",
+			base:   "a\r\nb\r\n",
+			ours:   "a\r\nB1\r\n",
+			theirs: "a\r\nB2\r\n",
+			want:   "a\r\n<<<<<<< local\r\nB1\r\n=======\r\nB2\r\n>>>>>>> mei\r\n",
+		},
+		{
+			name:      "CRLF local with LF base and source",
+			base:      "a\nb\nc\n",
+			ours:      "A\r\nb\r\nc\r\n",
+			theirs:    "a\nb\nC\n",
+			want:      "A\r\nb\r\nC\r\n",
+			wantClean: true,
+		},
+		{
+			name:      "LF local with CRLF source",
+			base:      "a\nb\nc\n",
+			ours:      "A\nb\nc\n",
+			theirs:    "a\r\nb\r\nC\r\n",
+			want:      "A\nb\nC\n",
+			wantClean: true,
+		},
+		{
+			name:      "mixed endings within one side",
+			base:      "a\nb\nc\n",
+			ours:      "a\nb\r\nc\n",
+			theirs:    "a\nb\nC\n",
+			want:      "a\nb\nC\n",
+			wantClean: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, clean := Merge(tt.base, tt.ours, tt.theirs)
+			if got != tt.want || clean != tt.wantClean {
+				t.Errorf("Merge(%q, %q, %q) = %q, %v; want %q, %v", tt.base, tt.ours, tt.theirs, got, clean, tt.want, tt.wantClean)
+			}
+		})
+	}
+}